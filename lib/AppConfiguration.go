@@ -17,12 +17,16 @@
 package lib
 
 import (
-	"errors"
+	"context"
+	"net/http"
+	"net/url"
 	"os"
+	"time"
 
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/messages"
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/models"
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
+	"github.com/IBM/go-sdk-core/v5/core"
 )
 
 // AppConfiguration : Struct having init and configInstance.
@@ -32,17 +36,74 @@ type AppConfiguration struct {
 	configurationHandlerInstance *ConfigurationHandler
 }
 
-// ContextOptions : Struct having PersistentCacheDirectory path, BootstrapFile (ConfigurationFile) path and LiveConfigUpdateEnabled flag.
+// ContextOptions : Options of the collection and environment set by SetContext.
 type ContextOptions struct {
+	// PersistentCacheDirectory is the directory the configurations are cached in across restarts
 	PersistentCacheDirectory string
-	BootstrapFile            string
-	ConfigurationFile        string
-	LiveConfigUpdateEnabled  bool
+	// PersistentCacheMaxAge ignores a persistent cache fetched longer ago than it. No max age when 0.
+	// A persistent cache of another guid, collection or environment is always ignored.
+	PersistentCacheMaxAge time.Duration
+	// BootstrapFile is the configurations file used until the configurations are fetched, or in the offline mode
+	BootstrapFile string
+	// ConfigurationFile is the deprecated name of BootstrapFile
+	ConfigurationFile string
+	// LiveConfigUpdateEnabled fetches the configurations from the server. When false, BootstrapFile is required.
+	LiveConfigUpdateEnabled bool
+	// RetryPolicy of the configuration fetch, the websocket connection and the metering requests. DefaultRetryPolicy when nil.
+	RetryPolicy *RetryPolicy
+	// RefreshMode selects how the configurations are kept up to date. RefreshModeWebSocket when empty.
+	RefreshMode RefreshMode
+	// PollingInterval is the interval of RefreshModePolling. DefaultPollingInterval when 0.
+	PollingInterval time.Duration
+	// PollingJitter randomises the polling interval by up to this fraction of it. DefaultPollingJitter when 0, none when negative.
+	PollingJitter float64
+	// WebSocketFallbackThreshold polls the configurations while the websocket has been disconnected for longer than it.
+	// No polling when 0.
+	WebSocketFallbackThreshold time.Duration
+	// WebSocketPingInterval is the interval the websocket is pinged at. DefaultWebSocketPingInterval when 0.
+	WebSocketPingInterval time.Duration
+	// WebSocketMaxSilence reconnects the websocket, and fetches the configurations again, when nothing was received for
+	// this long. DefaultWebSocketMaxSilence when 0.
+	WebSocketMaxSilence time.Duration
+	// WebSocketDebounceWindow serves the websocket notifications received within it of the first one with a single fetch.
+	// DefaultWebSocketDebounceWindow when 0, none when negative.
+	WebSocketDebounceWindow time.Duration
+	// WatchBootstrapFile reloads the BootstrapFile every time it changes, in the offline mode
+	WatchBootstrapFile bool
+	// BootstrapFileWatchInterval is the interval the BootstrapFile is checked at. DefaultBootstrapFileWatchInterval when 0.
+	BootstrapFileWatchInterval time.Duration
+	// IAMURL is the IAM token service the API key is exchanged at, when the client has no authenticator
+	IAMURL string
+	// Endpoint selects the public (ENDPOINT_PUBLIC, the default) or the private (ENDPOINT_PRIVATE) endpoint of the region
+	Endpoint string
+	// BaseURL replaces the endpoint of the region, such as http://localhost:8080
+	BaseURL string
+	// WebSocketBaseURL replaces the websocket endpoint, such as ws://localhost:8080. BaseURL with the ws or wss scheme when empty.
+	WebSocketBaseURL string
+	// HTTPClient sends the requests. The websocket uses its proxy and TLS configuration when its Transport is an *http.Transport.
+	HTTPClient *http.Client
+	// RequestTimeout bounds every request and the websocket handshake
+	RequestTimeout time.Duration
+	// Headers are added to every request and to the websocket handshake, replacing the SDK headers of the same name but Authorization
+	Headers http.Header
+	// MetricsRecorder receives the measurements of the client, such as the evaluations and the configuration fetches
+	MetricsRecorder MetricsRecorder
+}
+
+// ClientOptions : Struct having Region, GUID and APIKey of the App Configuration service instance a client created with NewClient connects to.
+// When Authenticator is set, it authenticates the requests and the websocket connection of the client instead of an IAM
+// authenticator created from the APIKey, which can then be left empty.
+type ClientOptions struct {
+	Region        string
+	GUID          string
+	APIKey        string
+	Authenticator core.Authenticator
 }
 
 var appConfigurationInstance *AppConfiguration
 
-// OverrideServerHost : Override server host
+// OverrideServerHost : Override server host. It also switches the IAM url to the test IAM endpoint.
+// Use the BaseURL and WebSocketBaseURL of the ContextOptions instead.
 var OverrideServerHost = ""
 
 // var log = logrus.New()
@@ -56,6 +117,37 @@ const REGION_EU_GB = "eu-gb"
 // REGION_AU_SYD : Sydney Region
 const REGION_AU_SYD = "au-syd"
 
+// REGION_US_EAST : Washington DC Region
+const REGION_US_EAST = "us-east"
+
+// REGION_EU_DE : Frankfurt Region
+const REGION_EU_DE = "eu-de"
+
+// REGION_EU_ES : Madrid Region
+const REGION_EU_ES = "eu-es"
+
+// REGION_CA_TOR : Toronto Region
+const REGION_CA_TOR = "ca-tor"
+
+// REGION_JP_TOK : Tokyo Region
+const REGION_JP_TOK = "jp-tok"
+
+// REGION_JP_OSA : Osaka Region
+const REGION_JP_OSA = "jp-osa"
+
+// REGION_BR_SAO : Sao Paulo Region
+const REGION_BR_SAO = "br-sao"
+
+// supportedRegions : Regions the App Configuration service is available in
+var supportedRegions = []string{REGION_US_SOUTH, REGION_EU_GB, REGION_AU_SYD, REGION_US_EAST, REGION_EU_DE, REGION_EU_ES,
+	REGION_CA_TOR, REGION_JP_TOK, REGION_JP_OSA, REGION_BR_SAO}
+
+// ENDPOINT_PUBLIC : Public endpoint of the region, <region>.apprapp.cloud.ibm.com
+const ENDPOINT_PUBLIC = "public"
+
+// ENDPOINT_PRIVATE : Private endpoint of the region, private.<region>.apprapp.cloud.ibm.com, reachable from the IBM Cloud private network
+const ENDPOINT_PRIVATE = "private"
+
 func init() {
 	log.SetLogLevel("info")
 }
@@ -69,74 +161,214 @@ func GetInstance() *AppConfiguration {
 	return appConfigurationInstance
 }
 
-// Init : Init App Configuration Instance
-func (ac *AppConfiguration) Init(region string, guid string, apikey string) {
+// NewClient : Create an App Configuration client which is independent of the instance returned by GetInstance.
+// Every client owns its configuration handler, cache, url builder, api manager and metering data, so that one process
+// can work with several App Configuration service instances, collections or environments at once.
+// Nothing runs in the background until SetContext is called. When Init fails, nil is returned with the error of Init.
+func NewClient(opts ClientOptions) (*AppConfiguration, error) {
+	log.Debug(messages.CreatingNewAppConfig)
+	ac := &AppConfiguration{
+		configurationHandlerInstance: newConfigurationHandler(),
+	}
+	var err error
+	if opts.Authenticator != nil {
+		err = ac.InitWithAuthenticator(opts.Region, opts.GUID, opts.Authenticator)
+	} else {
+		err = ac.Init(opts.Region, opts.GUID, opts.APIKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ac, nil
+}
+
+// Init : Init App Configuration Instance.
+// Returns ErrMissingRegion, ErrMissingGUID or ErrMissingAPIKey when the corresponding value is empty.
+func (ac *AppConfiguration) Init(region string, guid string, apikey string) error {
 	if len(region) == 0 || len(guid) == 0 || len(apikey) == 0 {
+		var err error
 		if len(region) == 0 {
 			log.Error(messages.RegionError)
+			err = ErrMissingRegion
 		}
 		if len(guid) == 0 {
 			log.Error(messages.GUIDError)
+			if err == nil {
+				err = ErrMissingGUID
+			}
 		}
 		if len(apikey) == 0 {
 			log.Error(messages.ApikeyError)
+			if err == nil {
+				err = ErrMissingAPIKey
+			}
 		}
-		return
+		return err
+	}
+	if ac.configurationHandlerInstance == nil {
+		ac.configurationHandlerInstance = GetConfigurationHandlerInstance()
 	}
-	ac.configurationHandlerInstance = GetConfigurationHandlerInstance()
 	ac.configurationHandlerInstance.Init(region, guid, apikey)
 	ac.isInitialized = true
+	return nil
+}
+
+// InitWithAuthenticator : Init App Configuration Instance with an authenticator, such as a container or a bearer token
+// authenticator, which authenticates the configuration fetch, the metering requests and the websocket connection instead
+// of an IAM API key.
+// Returns ErrMissingRegion, ErrMissingGUID or ErrMissingAuthenticator when the corresponding value is empty.
+func (ac *AppConfiguration) InitWithAuthenticator(region string, guid string, authenticator core.Authenticator) error {
+	if len(region) == 0 {
+		log.Error(messages.RegionError)
+		return ErrMissingRegion
+	}
+	if len(guid) == 0 {
+		log.Error(messages.GUIDError)
+		return ErrMissingGUID
+	}
+	if core.IsNil(authenticator) {
+		log.Error(messages.AuthenticatorError)
+		return ErrMissingAuthenticator
+	}
+	if ac.configurationHandlerInstance == nil {
+		ac.configurationHandlerInstance = GetConfigurationHandlerInstance()
+	}
+	ac.configurationHandlerInstance.InitWithAuthenticator(region, guid, authenticator)
+	ac.isInitialized = true
+	return nil
 }
 
-// SetContext : Set Context
-func (ac *AppConfiguration) SetContext(collectionID string, environmentID string, options ...ContextOptions) {
+// SetContext : Set Context.
+// Returns an error when the arguments are invalid. When the configurations are loaded for the first time, the error
+// of loading them (ErrConfigFetchFailed, ErrBootstrapFileUnreadable or ErrInvalidConfiguration) is returned too.
+// The context is set even in that case and the SDK keeps trying to fetch the configurations in the background.
+func (ac *AppConfiguration) SetContext(collectionID string, environmentID string, options ...ContextOptions) error {
 	log.Debug(messages.SettingContext)
 	if !ac.isInitialized {
 		log.Error(messages.CollectionIDError)
-		return
+		return ErrNotInitialized
 	}
 	if len(collectionID) == 0 {
 		log.Error(messages.CollectionIDValueError)
-		return
+		return ErrMissingCollectionID
 	}
 	if len(environmentID) == 0 {
 		log.Error(messages.EnvironmentIDValueError)
-		return
+		return ErrMissingEnvironmentID
 	}
+	var temp ContextOptions
 	switch len(options) {
 	case 0:
-		ac.configurationHandlerInstance.SetContext(collectionID, environmentID, ContextOptions{
-			LiveConfigUpdateEnabled: true,
-		})
+		temp = ContextOptions{LiveConfigUpdateEnabled: true}
 	case 1:
-		var temp = options[0]
+		temp = options[0]
 		if len(temp.ConfigurationFile) > 0 && len(temp.BootstrapFile) == 0 {
 			temp.BootstrapFile = temp.ConfigurationFile
 			log.Info(messages.ContextOptionsParameterDeprecation)
 		}
 		if !temp.LiveConfigUpdateEnabled && len(temp.BootstrapFile) == 0 {
 			log.Error(messages.BootstrapFileNotFoundError)
-			return
+			return ErrBootstrapFileRequired
 		}
-		ac.configurationHandlerInstance.SetContext(collectionID, environmentID, temp)
 	default:
 		log.Error(messages.IncorrectUsageOfContextOptions)
-		return
+		return ErrIncorrectContextOptions
+	}
+	if !temp.RefreshMode.isValid() {
+		log.Error(messages.RefreshModeError)
+		return ErrInvalidRefreshMode
 	}
+	if err := validateEndpoint(ac.configurationHandlerInstance.region, temp); err != nil {
+		return err
+	}
+	ac.configurationHandlerInstance.SetContext(collectionID, environmentID, temp)
 	ac.isInitializedConfig = true
 	// If the cache is not having data make a blocking call and load the data in in-memory cache , else use the existing cache data and asynchronously update it.
 	// This scenario can happen if the user uses setcontext second time in the code , in that case cache would not be empty.
-	if ac.configurationHandlerInstance.cache == nil {
+	if ac.configurationHandlerInstance.loadCache() == nil {
+		return ac.configurationHandlerInstance.loadData()
+	}
+	ac.configurationHandlerInstance.runInBackground(func() {
 		ac.configurationHandlerInstance.loadData()
-	} else {
-		go ac.configurationHandlerInstance.loadData()
+	})
+	return nil
+}
+
+// validateEndpoint : Check that the service of the region can be reached through the endpoint of the options, or that
+// the base urls of the options are valid.
+func validateEndpoint(region string, options ContextOptions) error {
+	if options.Endpoint != "" && options.Endpoint != ENDPOINT_PUBLIC && options.Endpoint != ENDPOINT_PRIVATE {
+		log.Error(messages.EndpointError)
+		return ErrInvalidEndpoint
+	}
+	if len(options.BaseURL) > 0 || len(options.WebSocketBaseURL) > 0 {
+		if !hasScheme(options.BaseURL, "http", "https") ||
+			(len(options.WebSocketBaseURL) > 0 && !hasScheme(options.WebSocketBaseURL, "ws", "wss")) {
+			log.Error(messages.BaseURLError)
+			return ErrInvalidBaseURL
+		}
+		return nil
 	}
+	if len(OverrideServerHost) > 0 {
+		return nil
+	}
+	for _, supportedRegion := range supportedRegions {
+		if region == supportedRegion {
+			return nil
+		}
+	}
+	log.Error(messages.UnknownRegionError)
+	return ErrUnknownRegion
+}
+
+// hasScheme : Check that rawURL is an absolute url with a host and one of the schemes
+func hasScheme(rawURL string, schemes ...string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || len(u.Host) == 0 {
+		return false
+	}
+	for _, scheme := range schemes {
+		if u.Scheme == scheme {
+			return true
+		}
+	}
+	return false
+}
+
+// WaitForReady : Block until the configurations are loaded from the server, the persistent cache or the bootstrap file,
+// and return the source they were loaded from. Returns an error wrapping the ctx error when ctx is done before that,
+// or ErrContextNotSet when SetContext has not been called successfully.
+func (ac *AppConfiguration) WaitForReady(ctx context.Context) (ConfigurationSource, error) {
+	if !ac.isInitializedConfig || ac.configurationHandlerInstance == nil {
+		log.Error(messages.CollectionInitError)
+		return "", ErrContextNotSet
+	}
+	source, err := ac.configurationHandlerInstance.waitForReady(ctx)
+	if err != nil {
+		log.Error(err)
+		return "", err
+	}
+	log.Info(messages.ConfigurationsReady, source)
+	return source, nil
+}
+
+// Close : Stop the background work of the client. The web socket is closed, pending retries are cancelled and the
+// metering data recorded so far is sent to the server. Blocks until all the background goroutines have exited or ctx
+// is done, in which case the ctx error is returned. The client must not be used after Close.
+func (ac *AppConfiguration) Close(ctx context.Context) error {
+	log.Debug(messages.ClosingClient)
+	if ac.configurationHandlerInstance == nil {
+		return nil
+	}
+	return ac.configurationHandlerInstance.close(ctx)
 }
 
 // FetchConfigurations : Fetch Configurations
 func (ac *AppConfiguration) FetchConfigurations() {
 	if ac.isInitialized && ac.isInitializedConfig {
-		go ac.configurationHandlerInstance.loadData()
+		ac.configurationHandlerInstance.runInBackground(func() {
+			ac.configurationHandlerInstance.refresh()
+		})
 	} else {
 		log.Error(messages.CollectionInitError)
 	}
@@ -151,40 +383,122 @@ func (ac *AppConfiguration) RegisterConfigurationUpdateListener(fhl configuratio
 	}
 }
 
-// GetFeature : Get Feature
+// AddConfigurationUpdateListener : Add a listener called with the changes every time the configurations are updated, in
+// addition to the one set by RegisterConfigurationUpdateListener. Listeners are called in the order they were added,
+// and the returned function removes the listener. It can be called once Init has succeeded.
+func (ac *AppConfiguration) AddConfigurationUpdateListener(listener ConfigurationUpdateListener) (unsubscribe func()) {
+	if !ac.isInitialized || ac.configurationHandlerInstance == nil {
+		log.Error(messages.CollectionIDError)
+		return func() {}
+	}
+	if listener == nil {
+		log.Error(messages.ConfigurationUpdateListenerMethodError)
+		return func() {}
+	}
+	return ac.configurationHandlerInstance.addConfigurationUpdateListener(listener)
+}
+
+// Status : Get the status of the client: whether the websocket is connected, when the configurations were last fetched,
+// the last fetch error and the source and version of the configurations in use. Returns an empty Status before a
+// successful SetContext.
+func (ac *AppConfiguration) Status() Status {
+	if !ac.isInitializedConfig || ac.configurationHandlerInstance == nil {
+		log.Error(messages.CollectionInitError)
+		return Status{}
+	}
+	return ac.configurationHandlerInstance.status()
+}
+
+// OnStatusChange : Add a listener called with the new status every time the status of the client changes. Listeners
+// are called one at a time, in the order they were added, and should return quickly. The returned function removes
+// the listener. It can be called once Init has succeeded.
+func (ac *AppConfiguration) OnStatusChange(listener StatusChangeListener) (unsubscribe func()) {
+	if !ac.isInitialized || ac.configurationHandlerInstance == nil {
+		log.Error(messages.CollectionIDError)
+		return func() {}
+	}
+	if listener == nil {
+		log.Error(messages.StatusChangeListenerMethodError)
+		return func() {}
+	}
+	return ac.configurationHandlerInstance.addStatusChangeListener(listener)
+}
+
+// WatchFeature : Watch the feature with the given id. The channel receives the feature when watching starts, if it exists,
+// and then every time an update of the configurations adds, modifies or removes it. A removed feature is received as
+// an empty models.Feature. A receiver that is slow gets the latest feature only, updates never wait for it.
+// The channel is closed when ctx is done or the client is closed.
+func (ac *AppConfiguration) WatchFeature(ctx context.Context, featureID string) <-chan models.Feature {
+	if !ac.isInitialized || ac.configurationHandlerInstance == nil {
+		log.Error(messages.CollectionIDError)
+		closed := make(chan models.Feature)
+		close(closed)
+		return closed
+	}
+	return ac.configurationHandlerInstance.watchFeature(ctx, featureID)
+}
+
+// WatchProperty : Watch the property with the given id. See WatchFeature
+func (ac *AppConfiguration) WatchProperty(ctx context.Context, propertyID string) <-chan models.Property {
+	if !ac.isInitialized || ac.configurationHandlerInstance == nil {
+		log.Error(messages.CollectionIDError)
+		closed := make(chan models.Property)
+		close(closed)
+		return closed
+	}
+	return ac.configurationHandlerInstance.watchProperty(ctx, propertyID)
+}
+
+// Snapshot : Get the configurations currently in use, which later updates leave untouched. Evaluating the features
+// and properties of one snapshot gives results consistent with a single configuration version.
+// Returns ErrContextNotSet before a successful SetContext and ErrConfigurationsNotLoaded until the configurations are loaded.
+func (ac *AppConfiguration) Snapshot() (*Snapshot, error) {
+	if !ac.isInitializedConfig || ac.configurationHandlerInstance == nil {
+		log.Error(messages.CollectionInitError)
+		return nil, ErrContextNotSet
+	}
+	snapshot := ac.configurationHandlerInstance.snapshot()
+	if snapshot.cache == nil {
+		log.Error(messages.InitError)
+		return nil, ErrConfigurationsNotLoaded
+	}
+	return snapshot, nil
+}
+
+// GetFeature : Get Feature. Returns ErrContextNotSet before a successful SetContext.
 func (ac *AppConfiguration) GetFeature(featureID string) (models.Feature, error) {
 	if ac.isInitializedConfig == true && ac.configurationHandlerInstance != nil {
 		return ac.configurationHandlerInstance.getFeature(featureID)
 	}
 	log.Error(messages.CollectionInitError)
-	return models.Feature{}, errors.New(messages.ErrorInvalidFeatureAction)
+	return models.Feature{}, ErrContextNotSet
 }
 
-// GetFeatures : Get Features
+// GetFeatures : Get Features. Returns ErrContextNotSet before a successful SetContext.
 func (ac *AppConfiguration) GetFeatures() (map[string]models.Feature, error) {
 	if ac.isInitializedConfig == true && ac.configurationHandlerInstance != nil {
 		return ac.configurationHandlerInstance.getFeatures()
 	}
 	log.Error(messages.CollectionInitError)
-	return nil, errors.New(messages.InitError)
+	return nil, ErrContextNotSet
 }
 
-// GetProperty : Get Property
+// GetProperty : Get Property. Returns ErrContextNotSet before a successful SetContext.
 func (ac *AppConfiguration) GetProperty(propertyID string) (models.Property, error) {
 	if ac.isInitializedConfig == true && ac.configurationHandlerInstance != nil {
 		return ac.configurationHandlerInstance.getProperty(propertyID)
 	}
 	log.Error(messages.CollectionInitError)
-	return models.Property{}, errors.New(messages.ErrorInvalidPropertyAction)
+	return models.Property{}, ErrContextNotSet
 }
 
-// GetProperties : Get Properties
+// GetProperties : Get Properties. Returns ErrContextNotSet before a successful SetContext.
 func (ac *AppConfiguration) GetProperties() (map[string]models.Property, error) {
 	if ac.isInitializedConfig == true && ac.configurationHandlerInstance != nil {
 		return ac.configurationHandlerInstance.getProperties()
 	}
 	log.Error(messages.CollectionInitError)
-	return nil, errors.New(messages.InitError)
+	return nil, ErrContextNotSet
 }
 
 // EnableDebug : Enable Debug
@@ -0,0 +1,146 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/messages"
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils"
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
+)
+
+// DefaultBootstrapFileWatchInterval : Interval the bootstrap file is checked for changes at when none is given
+const DefaultBootstrapFileWatchInterval = 2 * time.Second
+
+// bootstrapFileState : Modification time, size and content hash of the bootstrap file when it was last read
+type bootstrapFileState struct {
+	modTime time.Time
+	size    int64
+	hash    [sha256.Size]byte
+}
+
+// startBootstrapFileWatcher : Start polling the bootstrap file for changes, replacing the watcher of a previous context.
+// The configurations of the changed file are loaded through updateCacheAndListener, so that the listeners and the watches
+// are notified. A file which is not a valid configuration leaves the previous configurations in use.
+func (ch *ConfigurationHandler) startBootstrapFileWatcher(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultBootstrapFileWatchInterval
+	}
+	ch.mu.Lock()
+	if ch.stopBootstrapFileWatcher != nil {
+		ch.stopBootstrapFileWatcher()
+	}
+	parent := ch.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	ch.stopBootstrapFileWatcher = cancel
+	bootstrapFile, persistentCacheDirectory := ch.bootstrapFile, ch.persistentCacheDirectory
+	clock := ch.clock
+	ch.mu.Unlock()
+	if clock == nil {
+		clock = utils.SystemClock
+	}
+	log.Debug(messages.BootstrapFileWatchStarted, bootstrapFile)
+	// the file was loaded by loadData just before, so its current content is the one in use
+	state, _ := readBootstrapFileState(bootstrapFile)
+	ch.runInBackground(func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-clock.After(interval):
+			}
+			state = ch.reloadBootstrapFile(ctx, bootstrapFile, persistentCacheDirectory, state)
+		}
+	})
+}
+
+// reloadBootstrapFile : Load the bootstrap file when it changed since it was in the given state, and return its new state.
+// Nothing is loaded once ctx is done, the context the file belongs to having been replaced.
+func (ch *ConfigurationHandler) reloadBootstrapFile(ctx context.Context, bootstrapFile, persistentCacheDirectory string, state bootstrapFileState) bootstrapFileState {
+	info, err := os.Stat(bootstrapFile)
+	if err != nil {
+		log.Error(messages.BootstrapFileReloadError, err)
+		return state
+	}
+	if info.ModTime().Equal(state.modTime) && info.Size() == state.size {
+		return state
+	}
+	data, err := utils.ReadFile(bootstrapFile)
+	if err != nil {
+		log.Error(messages.BootstrapFileReloadError, err)
+		return state
+	}
+	newState := bootstrapFileState{modTime: info.ModTime(), size: info.Size(), hash: sha256.Sum256(data)}
+	if newState.hash == state.hash {
+		// only touched, the content is the one in use
+		return newState
+	}
+	log.Info(messages.BootstrapFileChanged, bootstrapFile)
+	// the new state is kept even when the file is not valid, so that the same malformed file is not reported again
+	if err := checkBootstrapFileKeys(data); err != nil {
+		log.Error(messages.BootstrapFileReloadError, err)
+		return newState
+	}
+	if ctx.Err() != nil {
+		return state
+	}
+	if err := ch.updateCacheAndListener(data, ConfigurationSourceBootstrapFile); err != nil {
+		log.Error(messages.BootstrapFileReloadError, err)
+		return newState
+	}
+	if len(persistentCacheDirectory) > 0 {
+		ch.storeInPersistentCache(data, "")
+	}
+	return newState
+}
+
+// checkBootstrapFileKeys : Check that the configurations have the features, properties and segments keys, so that a
+// file with another schema, which still parses, does not empty the configurations in use
+func checkBootstrapFileKeys(data []byte) error {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfiguration, err)
+	}
+	for _, key := range []string{"features", "properties", "segments"} {
+		if _, ok := keys[key]; !ok {
+			return fmt.Errorf("%w: %s%q", ErrInvalidConfiguration, messages.BootstrapFileKeyMissing, key)
+		}
+	}
+	return nil
+}
+
+// readBootstrapFileState : Read the modification time, size and content hash of the bootstrap file
+func readBootstrapFileState(bootstrapFile string) (bootstrapFileState, error) {
+	info, err := os.Stat(bootstrapFile)
+	if err != nil {
+		return bootstrapFileState{}, err
+	}
+	data, err := utils.ReadFile(bootstrapFile)
+	if err != nil {
+		return bootstrapFileState{}, err
+	}
+	return bootstrapFileState{modTime: info.ModTime(), size: info.Size(), hash: sha256.Sum256(data)}, nil
+}
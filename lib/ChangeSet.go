@@ -0,0 +1,91 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"sort"
+
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/models"
+)
+
+// ConfigurationUpdateListener : Called with the changes every time the configurations are updated
+type ConfigurationUpdateListener func(ChangeSet)
+
+// Changes : Ids of the entries added, removed and modified by an update, each sorted in ascending order
+type Changes struct {
+	Added    []string
+	Removed  []string
+	Modified []string
+}
+
+// IsEmpty : Check whether no entry was added, removed or modified
+func (c Changes) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Modified) == 0
+}
+
+// ChangeSet : Changes between the configurations before and after an update, and the source of the update
+type ChangeSet struct {
+	Source     ConfigurationSource
+	Features   Changes
+	Properties Changes
+	Segments   Changes
+}
+
+// IsEmpty : Check whether the update changed none of the features, properties and segments
+func (cs ChangeSet) IsEmpty() bool {
+	return cs.Features.IsEmpty() && cs.Properties.IsEmpty() && cs.Segments.IsEmpty()
+}
+
+// newChangeSet : Compare the previous cache, which is nil on the first load, with the new one
+func newChangeSet(previous *models.Cache, current *models.Cache, source ConfigurationSource) ChangeSet {
+	var previousFeatures, currentFeatures map[string]models.Feature
+	var previousProperties, currentProperties map[string]models.Property
+	var previousSegments, currentSegments map[string]models.Segment
+	if previous != nil {
+		previousFeatures, previousProperties, previousSegments = previous.FeatureMap, previous.PropertyMap, previous.SegmentMap
+	}
+	if current != nil {
+		currentFeatures, currentProperties, currentSegments = current.FeatureMap, current.PropertyMap, current.SegmentMap
+	}
+	return ChangeSet{
+		Source:     source,
+		Features:   diff(previousFeatures, currentFeatures, models.Feature.Equal),
+		Properties: diff(previousProperties, currentProperties, models.Property.Equal),
+		Segments:   diff(previousSegments, currentSegments, models.Segment.Equal),
+	}
+}
+
+func diff[T any](previous map[string]T, current map[string]T, equal func(T, T) bool) Changes {
+	var changes Changes
+	for id, value := range current {
+		previousValue, ok := previous[id]
+		if !ok {
+			changes.Added = append(changes.Added, id)
+		} else if !equal(previousValue, value) {
+			changes.Modified = append(changes.Modified, id)
+		}
+	}
+	for id := range previous {
+		if _, ok := current[id]; !ok {
+			changes.Removed = append(changes.Removed, id)
+		}
+	}
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Removed)
+	sort.Strings(changes.Modified)
+	return changes
+}
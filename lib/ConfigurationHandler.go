@@ -18,8 +18,10 @@ package lib
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/constants"
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/messages"
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/models"
@@ -30,31 +32,116 @@ import (
 	"net/http"
 	"path"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type configurationUpdateListenerFunc func()
 
+// ConfigurationSource : Source from which the configurations in the cache were loaded
+type ConfigurationSource string
+
+const (
+	// ConfigurationSourceServer : Configurations fetched from the App Configuration server
+	ConfigurationSourceServer ConfigurationSource = "SERVER"
+	// ConfigurationSourceWebSocket : Configurations fetched from the App Configuration server on a websocket notification
+	ConfigurationSourceWebSocket ConfigurationSource = "WEBSOCKET"
+	// ConfigurationSourcePolling : Configurations fetched from the App Configuration server by polling
+	ConfigurationSourcePolling ConfigurationSource = "POLLING"
+	// ConfigurationSourcePersistentCache : Configurations read from the persistent cache directory
+	ConfigurationSourcePersistentCache ConfigurationSource = "PERSISTENT_CACHE"
+	// ConfigurationSourceBootstrapFile : Configurations read from the bootstrap file
+	ConfigurationSourceBootstrapFile ConfigurationSource = "BOOTSTRAP_FILE"
+)
+
+// DefaultWebSocketPingInterval : Interval the web socket is pinged at when none is given
+const DefaultWebSocketPingInterval = 30 * time.Second
+
+// DefaultWebSocketMaxSilence : Silence of the server after which the web socket is reconnected when none is given
+const DefaultWebSocketMaxSilence = 90 * time.Second
+
+// DefaultWebSocketDebounceWindow : Window the web socket notifications are coalesced within when none is given
+const DefaultWebSocketDebounceWindow = 500 * time.Millisecond
+
+// webSocketWriteWait : Longest time writing a ping to the web socket may take
+const webSocketWriteWait = 10 * time.Second
+
+// updateListener : Configuration update listener with the id it is unsubscribed by
+type updateListener struct {
+	id       uint64
+	listener ConfigurationUpdateListener
+}
+
 // ConfigurationHandler : Configuration Handler
 type ConfigurationHandler struct {
 	isInitialized               bool
 	collectionID                string
 	environmentID               string
 	apikey                      string
+	authenticator               core.Authenticator
 	guid                        string
 	region                      string
 	urlBuilder                  *utils.URLBuilder
+	apiManager                  *utils.APIManager
+	metering                    *utils.Metering
 	appConfig                   *AppConfiguration
-	cache                       *models.Cache
+	cache                       atomic.Value
+	cacheSource                 ConfigurationSource
+	ready                       chan struct{}
+	readyOnce                   sync.Once
 	configurationUpdateListener configurationUpdateListenerFunc
+	updateListeners             []updateListener
+	nextUpdateListenerID        uint64
+	updateListenersMu           sync.Mutex
+	featureWatches              watchRegistry[models.Feature]
+	propertyWatches             watchRegistry[models.Property]
 	persistentCacheDirectory    string
 	bootstrapFile               string
 	liveConfigUpdateEnabled     bool
+	refreshMode                 RefreshMode
+	pollingInterval             time.Duration
+	pollingJitter               float64
+	webSocketFallbackThreshold  time.Duration
+	stopPolling                 context.CancelFunc
+	webSocketConnected          bool
+	webSocketReconnecting       bool
+	webSocketPingInterval       time.Duration
+	webSocketMaxSilence         time.Duration
+	webSocketDebounceWindow     time.Duration
+	fetchRequests               chan struct{}
+	fetchMu                     sync.Mutex
+	webSocketDownSince          time.Time
+	watchBootstrapFile          bool
+	bootstrapFileWatchInterval  time.Duration
+	stopBootstrapFileWatcher    context.CancelFunc
 	persistentData              []byte
-	retryCount                  int
-	retryInterval               int64
+	persistentCacheMaxAge       time.Duration
+	etag                        string
+	checksum                    string
+	checksumCache               *models.Cache
+	persistentCacheSequence     uint64
+	persistentCacheStored       uint64
+	persistentCacheMu           sync.Mutex
+	lastSuccessfulFetch         time.Time
+	lastError                   error
+	lastErrorTime               time.Time
+	consecutiveFailures         int
+	statusListeners             []statusChangeListener
+	nextStatusListenerID        uint64
+	statusListenersMu           sync.Mutex
+	notifiedStatus              Status
+	statusMu                    sync.Mutex
+	metricsRecorder             utils.MetricsRecorder
+	retryPolicy                 *utils.RetryPolicy
+	clock                       utils.Clock
+	webSocketBackoff            *utils.Backoff
 	socketConnection            *websocket.Conn
 	socketConnectionResponse    *http.Response
+	stopRetry                   context.CancelFunc
+	ctx                         context.Context
+	cancel                      context.CancelFunc
+	closed                      bool
+	backgroundTasks             sync.WaitGroup
 	mu                          sync.Mutex
 }
 
@@ -63,82 +150,296 @@ var configurationHandlerInstance *ConfigurationHandler
 // GetConfigurationHandlerInstance : Get Configuration Handler Instance
 func GetConfigurationHandlerInstance() *ConfigurationHandler {
 	if configurationHandlerInstance == nil {
-		configurationHandlerInstance = new(ConfigurationHandler)
+		configurationHandlerInstance = &ConfigurationHandler{
+			urlBuilder: utils.GetInstance(),
+			apiManager: utils.GetAPIManagerInstance(),
+		}
 	}
 	return configurationHandlerInstance
 }
 
+// newConfigurationHandler : Create a Configuration Handler owning its own url builder, api manager and metering instance.
+// Nothing runs in the background until SetContext is called.
+func newConfigurationHandler() *ConfigurationHandler {
+	urlBuilder := utils.NewURLBuilder()
+	apiManager := utils.NewAPIManager(urlBuilder)
+	return &ConfigurationHandler{
+		urlBuilder: urlBuilder,
+		apiManager: apiManager,
+		metering:   utils.NewMetering(urlBuilder, apiManager),
+	}
+}
+
 // Init : Init App Configuration Instance
 func (ch *ConfigurationHandler) Init(region, guid, apikey string) {
 	ch.region = region
 	ch.guid = guid
 	ch.apikey = apikey
+	ch.authenticator = nil
+}
+
+// InitWithAuthenticator : Init App Configuration Instance with an authenticator used instead of the IAM API key
+func (ch *ConfigurationHandler) InitWithAuthenticator(region, guid string, authenticator core.Authenticator) {
+	ch.region = region
+	ch.guid = guid
+	ch.apikey = ""
+	ch.authenticator = authenticator
 }
 
 // SetContext : Set Context
 func (ch *ConfigurationHandler) SetContext(collectionID, environmentID string, options ContextOptions) {
+	ch.mu.Lock()
+	if ch.stopBootstrapFileWatcher != nil {
+		// the watcher starts again with the bootstrap file of the new context once it is loaded
+		ch.stopBootstrapFileWatcher()
+		ch.stopBootstrapFileWatcher = nil
+	}
+	ch.mu.Unlock()
 	ch.collectionID = collectionID
 	ch.environmentID = environmentID
-	ch.urlBuilder = utils.GetInstance()
+	if ch.urlBuilder == nil {
+		ch.urlBuilder = utils.GetInstance()
+	}
 	ch.urlBuilder.Init(ch.collectionID, ch.environmentID, ch.region, ch.guid, ch.apikey, OverrideServerHost)
-	utils.GetMeteringInstance().Init(ch.guid, environmentID, collectionID)
+	if len(options.BaseURL) > 0 {
+		ch.urlBuilder.SetBaseURLs(options.BaseURL, options.WebSocketBaseURL)
+	} else if options.Endpoint == ENDPOINT_PRIVATE {
+		ch.urlBuilder.UsePrivateEndpoint()
+	}
+	if len(options.IAMURL) > 0 {
+		ch.urlBuilder.SetIAMURL(options.IAMURL)
+	}
+	if ch.authenticator != nil {
+		ch.urlBuilder.SetAuthenticator(ch.authenticator)
+	}
+	if ch.apiManager == nil {
+		ch.apiManager = utils.GetAPIManagerInstance()
+	}
+	if ch.metering == nil {
+		ch.metering = utils.GetMeteringInstance()
+	}
+	ch.metering.Init(ch.guid, environmentID, collectionID)
+	transport := utils.TransportOptions{
+		HTTPClient:     options.HTTPClient,
+		RequestTimeout: options.RequestTimeout,
+		Headers:        options.Headers,
+	}
+	ch.apiManager.SetTransportOptions(transport)
+	if authenticator, ok := ch.urlBuilder.GetAuthenticator().(*core.IamAuthenticator); ok && options.HTTPClient != nil {
+		// the token requests go through the same proxy and TLS configuration as the service requests
+		authenticator.Client = transport.Client()
+	}
+	retryPolicy := utils.DefaultRetryPolicy()
+	if options.RetryPolicy != nil {
+		retryPolicy = *options.RetryPolicy
+	}
+	ch.metering.SetRetryPolicy(retryPolicy, ch.clock)
+	ch.metering.SetMetricsRecorder(options.MetricsRecorder)
+	ch.metering.Start()
+	ch.mu.Lock()
 	ch.persistentCacheDirectory = options.PersistentCacheDirectory
+	ch.persistentCacheMaxAge = options.PersistentCacheMaxAge
 	ch.bootstrapFile = options.BootstrapFile
 	ch.liveConfigUpdateEnabled = options.LiveConfigUpdateEnabled
+	ch.watchBootstrapFile = options.WatchBootstrapFile
+	ch.bootstrapFileWatchInterval = options.BootstrapFileWatchInterval
+	ch.refreshMode = options.RefreshMode
+	if ch.refreshMode == "" {
+		ch.refreshMode = RefreshModeWebSocket
+	}
+	ch.pollingInterval = options.PollingInterval
+	ch.pollingJitter = options.PollingJitter
+	if ch.pollingJitter == 0 {
+		ch.pollingJitter = DefaultPollingJitter
+	}
+	ch.webSocketFallbackThreshold = options.WebSocketFallbackThreshold
+	ch.webSocketPingInterval = options.WebSocketPingInterval
+	ch.webSocketMaxSilence = options.WebSocketMaxSilence
+	ch.webSocketDebounceWindow = options.WebSocketDebounceWindow
+	ch.metricsRecorder = options.MetricsRecorder
+	if ch.stopPolling != nil {
+		// polling starts again with the options of the new context once the configurations are fetched
+		ch.stopPolling()
+		ch.stopPolling = nil
+	}
+	ch.retryPolicy = &retryPolicy
+	ch.webSocketBackoff = utils.NewReconnectBackoff(retryPolicy, ch.clock)
+	if ch.ready == nil {
+		ch.ready = make(chan struct{})
+	}
+	if ch.ctx == nil {
+		ch.ctx, ch.cancel = context.WithCancel(context.Background())
+	}
+	ch.mu.Unlock()
 	ch.isInitialized = true
-	ch.retryCount = 3
-	ch.retryInterval = 600
 }
-func (ch *ConfigurationHandler) loadData() {
+func (ch *ConfigurationHandler) loadData() error {
 	if !ch.isInitialized {
 		log.Error(messages.ConfigurationHandlerInitError)
+		return ErrNotInitialized
 	}
+	// loading carries on when a step fails, so that the later sources can still provide the configurations.
+	// The error of the first step that failed is returned.
+	var loadErr error
+	var persistentChangeSet ChangeSet
 	if len(ch.persistentCacheDirectory) > 0 {
-		ch.persistentData = utils.ReadFiles(path.Join(ch.persistentCacheDirectory, constants.ConfigurationFile))
+		var etag string
+		ch.persistentData, etag = ch.readPersistentCache()
 		if !bytes.Equal(ch.persistentData, []byte(`{}`)) {
 			// no updating the listener here. Only updating cache is enough
-			ch.saveInCache(ch.persistentData)
+			var err error
+			persistentChangeSet, err = ch.saveInCache(ch.persistentData, ConfigurationSourcePersistentCache)
+			if err == nil {
+				ch.setETag(etag)
+				ch.notifyStatusChange()
+			} else {
+				// a persistent cache which cannot be loaded is ignored, so that the bootstrap file is loaded instead
+				log.Error(messages.PersistentCacheIgnored, err)
+				ch.persistentData = []byte(`{}`)
+			}
 		}
 	}
 	if len(ch.bootstrapFile) > 0 {
 		log.Debug(messages.BootstrapFileProvided)
 		if len(ch.persistentCacheDirectory) > 0 {
 			if bytes.Equal(ch.persistentData, []byte(`{}`)) {
-				bootstrapFileData := utils.ReadFiles(ch.bootstrapFile)
-				go utils.StoreFiles(string(bootstrapFileData), ch.persistentCacheDirectory)
-				ch.updateCacheAndListener(bootstrapFileData)
+				bootstrapFileData, err := ch.readBootstrapFile()
+				if err == nil {
+					err = ch.updateCacheAndListener(bootstrapFileData, ConfigurationSourceBootstrapFile)
+				}
+				if err == nil {
+					ch.storeInPersistentCache(bootstrapFileData, "")
+				} else if loadErr == nil {
+					loadErr = err
+				}
 			} else {
-				// update the only listener here. Because, cache is already updated above (line 100)
-				if ch.configurationUpdateListener != nil {
-					ch.configurationUpdateListener()
+				// update the only listener here. Because, cache is already updated above
+				if loadErr == nil {
+					ch.notifyConfigurationUpdateListeners(persistentChangeSet)
 				}
 			}
 		} else {
-			bootstrapFileData := utils.ReadFiles(ch.bootstrapFile)
-			ch.updateCacheAndListener(bootstrapFileData)
+			bootstrapFileData, err := ch.readBootstrapFile()
+			if err == nil {
+				err = ch.updateCacheAndListener(bootstrapFileData, ConfigurationSourceBootstrapFile)
+			}
+			if err != nil && loadErr == nil {
+				loadErr = err
+			}
 		}
 	}
 	if ch.liveConfigUpdateEnabled {
-		ch.FetchConfigurationData()
+		if err := ch.FetchConfigurationData(); err != nil && loadErr == nil {
+			loadErr = err
+		}
+	} else if ch.watchBootstrapFile && len(ch.bootstrapFile) > 0 {
+		ch.startBootstrapFileWatcher(ch.bootstrapFileWatchInterval)
+	}
+	return loadErr
+}
+
+// waitForReady : Wait until the cache is loaded for the first time and return the source it was loaded from
+func (ch *ConfigurationHandler) waitForReady(ctx context.Context) (ConfigurationSource, error) {
+	ch.mu.Lock()
+	ready := ch.ready
+	ch.mu.Unlock()
+	if ready == nil {
+		return "", ErrContextNotSet
+	}
+	select {
+	case <-ready:
+		ch.mu.Lock()
+		defer ch.mu.Unlock()
+		return ch.cacheSource, nil
+	case <-ctx.Done():
+		return "", fmt.Errorf("%s: %w", messages.ConfigurationsNotReady, ctx.Err())
 	}
 }
 
+// storeInPersistentCache : Write data to the persistent cache in the background, in an envelope recording the context it
+// belongs to, the time it was fetched at and its ETag. The writes are serialised, and a write which starts after a more
+// recent one has completed is skipped, so that older configurations never replace newer ones.
+func (ch *ConfigurationHandler) storeInPersistentCache(data []byte, etag string) {
+	persistentCache, err := json.Marshal(utils.NewPersistentCache(ch.guid, ch.collectionID, ch.environmentID, etag, time.Now(), data))
+	if err != nil {
+		log.Error(messages.EncodeJSONErr, err)
+		return
+	}
+	ch.mu.Lock()
+	ch.persistentCacheSequence++
+	sequence := ch.persistentCacheSequence
+	ch.mu.Unlock()
+	ch.runInBackground(func() {
+		ch.persistentCacheMu.Lock()
+		defer ch.persistentCacheMu.Unlock()
+		if sequence < ch.persistentCacheStored {
+			return
+		}
+		utils.StoreFiles(string(persistentCache), ch.persistentCacheDirectory)
+		ch.persistentCacheStored = sequence
+	})
+}
+
+// readPersistentCache : Read the configurations of the persistent cache, and the ETag they were fetched with.
+// A persistent cache which is corrupt, belongs to another context or is older than the max age is logged and ignored,
+// and read as an empty json object.
+func (ch *ConfigurationHandler) readPersistentCache() ([]byte, string) {
+	data := utils.ReadFiles(path.Join(ch.persistentCacheDirectory, constants.ConfigurationFile))
+	if bytes.Equal(data, []byte(`{}`)) {
+		return data, ""
+	}
+	persistentCache, err := utils.ParsePersistentCache(data)
+	if err == nil && !persistentCache.Matches(ch.guid, ch.collectionID, ch.environmentID) {
+		err = errors.New(messages.PersistentCacheContextMismatch)
+	}
+	if err == nil && ch.persistentCacheMaxAge > 0 && time.Since(persistentCache.FetchedAt) > ch.persistentCacheMaxAge {
+		err = errors.New(messages.PersistentCacheExpired)
+	}
+	if err != nil {
+		log.Error(messages.PersistentCacheIgnored, err)
+		return []byte(`{}`), ""
+	}
+	return persistentCache.Configurations, persistentCache.ETag
+}
+
+func (ch *ConfigurationHandler) readBootstrapFile() ([]byte, error) {
+	bootstrapFileData, err := utils.ReadFile(ch.bootstrapFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBootstrapFileUnreadable, err)
+	}
+	return bootstrapFileData, nil
+}
+
 // FetchConfigurationData : Fetch Configuration Data
-func (ch *ConfigurationHandler) FetchConfigurationData() {
+func (ch *ConfigurationHandler) FetchConfigurationData() error {
 	log.Debug(messages.FetchConfigurationData)
 	if ch.isInitialized {
-		ch.fetchFromAPI()
-		go ch.startWebSocket()
+		err := ch.fetchFromAPI()
+		ch.mu.Lock()
+		refreshMode, fallbackThreshold := ch.refreshMode, ch.webSocketFallbackThreshold
+		ch.mu.Unlock()
+		switch refreshMode {
+		case RefreshModePolling:
+			ch.startPolling()
+		case RefreshModeManual:
+		default:
+			ch.runInBackground(ch.startWebSocket)
+			if fallbackThreshold > 0 {
+				ch.startPolling()
+			}
+		}
+		return err
 	}
+	return ErrNotInitialized
 }
-func (ch *ConfigurationHandler) saveInCache(data []byte) {
-	ch.mu.Lock()
-	defer ch.mu.Unlock()
+
+// saveInCache : Replace the cache with the configurations in data and return the changes from the previous cache
+func (ch *ConfigurationHandler) saveInCache(data []byte, source ConfigurationSource) (ChangeSet, error) {
 	configResponse := models.ConfigResponse{}
 	err := json.Unmarshal(data, &configResponse)
 	if err != nil {
 		log.Error(messages.UnmarshalJSONErr, err)
-		return
+		return ChangeSet{}, fmt.Errorf("%w: %v", ErrInvalidConfiguration, err)
 	}
 	log.Debug(configResponse)
 	featureMap := make(map[string]models.Feature)
@@ -156,135 +457,702 @@ func (ch *ConfigurationHandler) saveInCache(data []byte) {
 		segmentMap[segment.GetSegmentID()] = segment
 	}
 	log.Debug(messages.SetInMemoryCache)
-	models.SetCache(featureMap, propertyMap, segmentMap)
-	ch.cache = models.GetCacheInstance()
-}
-func (ch *ConfigurationHandler) updateCacheAndListener(data []byte) {
-	ch.saveInCache(data)
-	if ch.configurationUpdateListener != nil {
-		ch.configurationUpdateListener()
+	ch.mu.Lock()
+	previous := ch.loadCache()
+	cache := models.NewCache(featureMap, propertyMap, segmentMap, ch.metering)
+	ch.storeCache(cache)
+	ch.cacheSource = source
+	// the ETag of the previous configurations does not describe the new ones
+	ch.etag = ""
+	ch.checksum = utils.Checksum(data)
+	ch.checksumCache = cache
+	if ch.ready != nil {
+		ch.readyOnce.Do(func() {
+			close(ch.ready)
+		})
 	}
+	changeSet := newChangeSet(previous, cache, source)
+	ch.notifyWatches(cache, changeSet)
+	recorder, collectionID, environmentID := ch.loadMetricsRecorder(), ch.collectionID, ch.environmentID
+	ch.mu.Unlock()
+	recorder.RecordCacheSize(collectionID, environmentID, len(featureMap), len(propertyMap), len(segmentMap))
+	return changeSet, nil
 }
-func (ch *ConfigurationHandler) fetchFromAPI() {
-	if ch.isInitialized {
-		ch.retryCount--
-		builder := core.NewRequestBuilder(core.GET)
-		builder.AddQuery("environment_id", ch.environmentID)
-		pathParamsMap := map[string]string{
-			"guid":          ch.guid,
-			"collection_id": ch.collectionID,
-		}
-		_, err := builder.ResolveRequestURL(ch.urlBuilder.GetBaseServiceURL(), `/apprapp/feature/v1/instances/{guid}/collections/{collection_id}/config`, pathParamsMap)
-		if err != nil {
-			return
+
+// loadCache : Get the current cache, nil until the configurations are loaded. The cache is never modified once
+// stored, a configuration update stores a new one, so it can be read without locking.
+func (ch *ConfigurationHandler) loadCache() *models.Cache {
+	cache, _ := ch.cache.Load().(*models.Cache)
+	return cache
+}
+
+// storeCache : Replace the current cache
+func (ch *ConfigurationHandler) storeCache(cache *models.Cache) {
+	ch.cache.Store(cache)
+}
+
+// notifyWatches : Send the features and properties added, modified or removed to their watchers. Removed entries are
+// sent as empty values. Must be called with mu held, right after the cache is replaced.
+func (ch *ConfigurationHandler) notifyWatches(cache *models.Cache, changeSet ChangeSet) {
+	notifiedFeatures := make(map[string]bool)
+	for _, ids := range [][]string{changeSet.Features.Added, changeSet.Features.Modified, changeSet.Features.Removed} {
+		for _, featureID := range ids {
+			ch.featureWatches.notify(featureID, cache.FeatureMap[featureID])
+			notifiedFeatures[featureID] = true
+		}
+	}
+	notifiedProperties := make(map[string]bool)
+	for _, ids := range [][]string{changeSet.Properties.Added, changeSet.Properties.Modified, changeSet.Properties.Removed} {
+		for _, propertyID := range ids {
+			ch.propertyWatches.notify(propertyID, cache.PropertyMap[propertyID])
+			notifiedProperties[propertyID] = true
 		}
-		builder.AddHeader("Accept", "application/json")
-		builder.AddHeader("User-Agent", constants.UserAgent)
-		response := utils.GetAPIManagerInstance().Request(builder)
-		if response != nil && response.StatusCode >= 200 && response.StatusCode <= 299 {
-			if ch.liveConfigUpdateEnabled {
-				jsonData, _ := json.Marshal(response.Result)
-				// asynchronously write the response to persistent volume, if enabled
-				if len(ch.persistentCacheDirectory) > 0 {
-					go utils.StoreFiles(string(jsonData), ch.persistentCacheDirectory)
+	}
+	if changeSet.Segments.IsEmpty() {
+		return
+	}
+	// the entries received before evaluate the segments of the previous cache, so the unchanged entries using a
+	// changed segment are sent again, bound to the new cache
+	changedSegments := make(map[string]bool)
+	for _, ids := range [][]string{changeSet.Segments.Added, changeSet.Segments.Modified, changeSet.Segments.Removed} {
+		for _, segmentID := range ids {
+			changedSegments[segmentID] = true
+		}
+	}
+	for featureID, feature := range cache.FeatureMap {
+		if !notifiedFeatures[featureID] && usesSegments(feature.GetSegmentRules(), changedSegments) {
+			ch.featureWatches.notify(featureID, feature)
+		}
+	}
+	for propertyID, property := range cache.PropertyMap {
+		if !notifiedProperties[propertyID] && usesSegments(property.GetSegmentRules(), changedSegments) {
+			ch.propertyWatches.notify(propertyID, property)
+		}
+	}
+}
+
+// usesSegments : Check whether the segment rules refer to any of the segments
+func usesSegments(segmentRules []models.SegmentRule, segments map[string]bool) bool {
+	for _, segmentRule := range segmentRules {
+		for _, rule := range segmentRule.GetRules() {
+			for _, segmentID := range rule.Segments {
+				if segments[segmentID] {
+					return true
 				}
-				// load the configurations in the response to cache maps
-				ch.updateCacheAndListener(jsonData)
 			}
-		} else {
-			if ch.retryCount > 0 {
-				if response != nil {
-					if response.Result != nil {
-						log.Error(response.Result)
-					} else {
-						log.Error(string(response.RawResult))
-					}
-				} else {
-					log.Error(messages.ConfigAPIError)
+		}
+	}
+	return false
+}
+
+// watchFeature : Watch the feature until ctx is done or the handler is closed. The current feature, if any, is sent first.
+func (ch *ConfigurationHandler) watchFeature(ctx context.Context, featureID string) <-chan models.Feature {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	w := ch.featureWatches.add(featureID)
+	if ch.closed {
+		ch.featureWatches.remove(featureID, w)
+		return w.values
+	}
+	if cache := ch.loadCache(); cache != nil {
+		if feature, ok := cache.FeatureMap[featureID]; ok {
+			w.send(feature)
+		}
+	}
+	go unwatchOnDone(ctx, ch.closing(), func() {
+		ch.featureWatches.remove(featureID, w)
+	})
+	return w.values
+}
+
+// watchProperty : Watch the property until ctx is done or the handler is closed. The current property, if any, is sent first.
+func (ch *ConfigurationHandler) watchProperty(ctx context.Context, propertyID string) <-chan models.Property {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	w := ch.propertyWatches.add(propertyID)
+	if ch.closed {
+		ch.propertyWatches.remove(propertyID, w)
+		return w.values
+	}
+	if cache := ch.loadCache(); cache != nil {
+		if property, ok := cache.PropertyMap[propertyID]; ok {
+			w.send(property)
+		}
+	}
+	go unwatchOnDone(ctx, ch.closing(), func() {
+		ch.propertyWatches.remove(propertyID, w)
+	})
+	return w.values
+}
+
+// closing : Channel closed when the handler is closed, nil before SetContext. Must be called with mu held.
+func (ch *ConfigurationHandler) closing() <-chan struct{} {
+	if ch.ctx == nil {
+		return nil
+	}
+	return ch.ctx.Done()
+}
+
+// unwatchOnDone : Call unwatch once ctx is done or closing is closed
+func unwatchOnDone(ctx context.Context, closing <-chan struct{}, unwatch func()) {
+	select {
+	case <-ctx.Done():
+	case <-closing:
+	}
+	unwatch()
+}
+func (ch *ConfigurationHandler) updateCacheAndListener(data []byte, source ConfigurationSource) error {
+	changeSet, err := ch.saveInCache(data, source)
+	if err != nil {
+		return err
+	}
+	ch.notifyStatusChange()
+	ch.notifyConfigurationUpdateListeners(changeSet)
+	return nil
+}
+
+// notifyConfigurationUpdateListeners : Call the registered listener and every added listener, in the order they were added.
+// A listener that panics does not prevent the others from being called.
+func (ch *ConfigurationHandler) notifyConfigurationUpdateListeners(changeSet ChangeSet) {
+	start := time.Now()
+	defer func() {
+		ch.getMetricsRecorder().RecordListenerLatency(time.Since(start))
+	}()
+	if ch.configurationUpdateListener != nil {
+		ch.configurationUpdateListener()
+	}
+	ch.updateListenersMu.Lock()
+	listeners := append([]updateListener(nil), ch.updateListeners...)
+	ch.updateListenersMu.Unlock()
+	for _, l := range listeners {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Error(messages.ConfigurationUpdateListenerMethodError, r)
 				}
-				ch.fetchFromAPI()
-			} else {
-				ch.retryCount = 3
-				time.AfterFunc(time.Second*time.Duration(ch.retryInterval), func() {
-					ch.fetchFromAPI()
-				})
+			}()
+			l.listener(changeSet)
+		}()
+	}
+}
+
+// addConfigurationUpdateListener : Add a listener and return the function removing it
+func (ch *ConfigurationHandler) addConfigurationUpdateListener(listener ConfigurationUpdateListener) func() {
+	ch.updateListenersMu.Lock()
+	defer ch.updateListenersMu.Unlock()
+	ch.nextUpdateListenerID++
+	id := ch.nextUpdateListenerID
+	ch.updateListeners = append(ch.updateListeners, updateListener{id: id, listener: listener})
+	return func() {
+		ch.updateListenersMu.Lock()
+		defer ch.updateListenersMu.Unlock()
+		for i, l := range ch.updateListeners {
+			if l.id == id {
+				ch.updateListeners = append(ch.updateListeners[:i:i], ch.updateListeners[i+1:]...)
+				return
 			}
 		}
-	} else {
+	}
+}
+
+func (ch *ConfigurationHandler) fetchFromAPI() error {
+	return ch.fetchFromAPIFor(ConfigurationSourceServer)
+}
+
+// refresh : Fetch the configurations from the server, or reload the bootstrap file in the offline mode. Unlike loadData,
+// the persistent cache is not read again and the websocket or polling is not restarted, so that the ETag of the
+// configurations in use is sent and the listeners are only notified of actual changes.
+func (ch *ConfigurationHandler) refresh() error {
+	ch.mu.Lock()
+	liveConfigUpdateEnabled, persistentCacheDirectory := ch.liveConfigUpdateEnabled, ch.persistentCacheDirectory
+	ch.mu.Unlock()
+	if liveConfigUpdateEnabled {
+		return ch.fetchFromAPI()
+	}
+	data, err := ch.readBootstrapFile()
+	if err != nil {
+		return err
+	}
+	if err := ch.updateCacheAndListener(data, ConfigurationSourceBootstrapFile); err != nil {
+		return err
+	}
+	if len(persistentCacheDirectory) > 0 {
+		ch.storeInPersistentCache(data, "")
+	}
+	return nil
+}
+
+// fetchFromAPIFor : Fetch the configurations from the server, recording source as the source of the update
+func (ch *ConfigurationHandler) fetchFromAPIFor(source ConfigurationSource) error {
+	if !ch.isInitialized {
 		log.Debug(messages.FetchFromAPISdkInitError)
+		return ErrNotInitialized
+	}
+	// one fetch at a time, so that the responses are applied in the order they were requested
+	ch.fetchMu.Lock()
+	defer ch.fetchMu.Unlock()
+	builder := core.NewRequestBuilder(core.GET)
+	builder.AddQuery("environment_id", ch.environmentID)
+	pathParamsMap := map[string]string{
+		"guid":          ch.guid,
+		"collection_id": ch.collectionID,
+	}
+	_, err := builder.ResolveRequestURL(ch.urlBuilder.GetBaseServiceURL(), `/apprapp/feature/v1/instances/{guid}/collections/{collection_id}/config`, pathParamsMap)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrConfigFetchFailed, err)
+	}
+	builder.AddHeader("Accept", "application/json")
+	builder.AddHeader("User-Agent", constants.UserAgent)
+	if etag := ch.getETag(); len(etag) > 0 {
+		builder.AddHeader("If-None-Match", etag)
+	}
+	var response *core.DetailedResponse
+	checkResponse := func() error {
+		if response != nil && ((response.StatusCode >= 200 && response.StatusCode <= 299) || response.StatusCode == http.StatusNotModified) {
+			return nil
+		}
+		if response != nil {
+			if response.Result != nil {
+				log.Error(response.Result)
+			} else {
+				log.Error(string(response.RawResult))
+			}
+			return fmt.Errorf("%w: status code %d", ErrConfigFetchFailed, response.StatusCode)
+		}
+		log.Error(messages.ConfigAPIError)
+		return ErrConfigFetchFailed
+	}
+	// closing the handler aborts the request in flight as well as the retries
+	ctx := ch.getContext()
+	builder.WithContext(ctx)
+	err = utils.Retry(ctx, ch.getRetryPolicy(), ch.clock, func() error {
+		start := time.Now()
+		response = ch.apiManager.Request(builder)
+		err := checkResponse()
+		ch.getMetricsRecorder().RecordFetch(time.Since(start), err)
+		if err != nil {
+			ch.recordFetchFailure(err)
+		}
+		return err
+	})
+	if err != nil {
+		policy := ch.getRetryPolicy()
+		ch.scheduleRetry(utils.Jitter(policy.CycleDelay(), policy.Jitter))
+		return err
+	}
+	if ch.liveConfigUpdateEnabled {
+		if response.StatusCode == http.StatusNotModified {
+			log.Debug(messages.ConfigurationsNotModified)
+			// the configurations in use, even when loaded from the persistent cache, are now the ones of the server
+			ch.setCacheSource(source)
+			ch.recordFetchSuccess()
+			return nil
+		}
+		jsonData, _ := json.Marshal(response.Result)
+		etag := response.Headers.Get("ETag")
+		if ch.isCurrentConfigurations(jsonData) {
+			log.Debug(messages.ConfigurationsNotModified)
+			ch.setETag(etag)
+			ch.setCacheSource(source)
+			ch.recordFetchSuccess()
+			return nil
+		}
+		// asynchronously write the response to persistent volume, if enabled
+		if len(ch.persistentCacheDirectory) > 0 {
+			ch.storeInPersistentCache(jsonData, etag)
+		}
+		// load the configurations in the response to cache maps
+		changeSet, err := ch.saveInCache(jsonData, source)
+		if err != nil {
+			ch.recordFetchFailure(err)
+			return err
+		}
+		ch.setETag(etag)
+		ch.recordFetchSuccess()
+		ch.notifyConfigurationUpdateListeners(changeSet)
+		return nil
+	}
+	ch.recordFetchSuccess()
+	return nil
+}
+
+// getETag : Get the ETag of the configurations in the cache, empty when they were not fetched from the server with one
+func (ch *ConfigurationHandler) getETag() string {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	if ch.loadCache() == nil {
+		return ""
+	}
+	return ch.etag
+}
+
+// setETag : Set the ETag of the configurations in the cache
+func (ch *ConfigurationHandler) setETag(etag string) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	ch.etag = etag
+}
+
+// setCacheSource : Set the source the configurations in the cache were last confirmed by
+func (ch *ConfigurationHandler) setCacheSource(source ConfigurationSource) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	ch.cacheSource = source
+}
+
+// isCurrentConfigurations : Check whether data has the same configurations as the ones the cache was built from
+func (ch *ConfigurationHandler) isCurrentConfigurations(data []byte) bool {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	cache := ch.loadCache()
+	return cache != nil && cache == ch.checksumCache && utils.Checksum(data) == ch.checksum
+}
+
+// getMetricsRecorder : Get the recorder of the measurements of the client, which discards them when none is set
+func (ch *ConfigurationHandler) getMetricsRecorder() utils.MetricsRecorder {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return ch.loadMetricsRecorder()
+}
+
+// loadMetricsRecorder : Get the recorder of the measurements of the client. Must be called with mu held.
+func (ch *ConfigurationHandler) loadMetricsRecorder() utils.MetricsRecorder {
+	if ch.metricsRecorder == nil {
+		return utils.NopMetricsRecorder{}
+	}
+	return ch.metricsRecorder
+}
+
+// getRetryPolicy : Get the retry policy of the requests to the server
+func (ch *ConfigurationHandler) getRetryPolicy() utils.RetryPolicy {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	if ch.retryPolicy == nil {
+		return utils.DefaultRetryPolicy()
+	}
+	return *ch.retryPolicy
+}
+
+// scheduleRetry : Fetch the configurations again after the given delay, unless the handler is closed before that.
+// A retry already scheduled is cancelled.
+func (ch *ConfigurationHandler) scheduleRetry(delay time.Duration) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	if ch.closed {
+		return
+	}
+	if ch.stopRetry != nil {
+		ch.stopRetry()
+	}
+	parent := ch.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	ch.stopRetry = cancel
+	clock := ch.clock
+	if clock == nil {
+		clock = utils.SystemClock
+	}
+	timer := clock.After(delay)
+	ch.backgroundTasks.Add(1)
+	go func() {
+		defer ch.backgroundTasks.Done()
+		select {
+		case <-ctx.Done():
+		case <-timer:
+			ch.fetchFromAPI()
+		}
+	}()
+}
+
+// runInBackground : Run f in a new goroutine which close waits for. Nothing is run once the handler is closed.
+func (ch *ConfigurationHandler) runInBackground(f func()) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	if ch.closed {
+		return
+	}
+	ch.backgroundTasks.Add(1)
+	go func() {
+		defer ch.backgroundTasks.Done()
+		f()
+	}()
+}
+
+func (ch *ConfigurationHandler) isClosed() bool {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return ch.closed
+}
+
+func (ch *ConfigurationHandler) getContext() context.Context {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	if ch.ctx == nil {
+		return context.Background()
+	}
+	return ch.ctx
+}
+
+// close : Close the web socket, cancel the pending retries, flush the metering data and wait for the background goroutines to exit
+func (ch *ConfigurationHandler) close(ctx context.Context) error {
+	ch.mu.Lock()
+	if ch.closed {
+		ch.mu.Unlock()
+		return nil
+	}
+	ch.closed = true
+	if ch.cancel != nil {
+		ch.cancel()
+	}
+	if ch.socketConnection != nil {
+		ch.socketConnection.Close()
+	}
+	ch.featureWatches.closeAll()
+	ch.propertyWatches.closeAll()
+	ch.mu.Unlock()
+	ch.notifyStatusChange()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ch.backgroundTasks.Wait()
+		if ch.metering != nil {
+			ch.metering.Close()
+		}
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
 func (ch *ConfigurationHandler) startWebSocket() {
 	defer utils.GracefullyHandleError()
 	log.Debug(messages.StartWebSocket)
-	h := http.Header{"Authorization": []string{ch.urlBuilder.GetToken()}}
-	var err error
+	transport := ch.apiManager.GetTransportOptions()
+	h := transport.AddHeaders(http.Header{"Authorization": []string{ch.urlBuilder.GetToken()}})
+	ch.mu.Lock()
 	if ch.socketConnection != nil {
 		ch.socketConnection.Close()
+		ch.socketConnection = nil
 	}
-	ch.socketConnection, ch.socketConnectionResponse, err = websocket.DefaultDialer.Dial(ch.urlBuilder.GetWebSocketURL(), h)
+	ch.mu.Unlock()
+	socketConnection, socketConnectionResponse, err := transport.WebSocketDialer().DialContext(ch.getContext(), ch.urlBuilder.GetWebSocketURL(), h)
 	if err != nil {
-		if ch.socketConnectionResponse != nil {
-			log.Error(messages.WebSocketConnectErr, err, ch.socketConnectionResponse.StatusCode)
+		if socketConnectionResponse != nil {
+			log.Error(messages.WebSocketConnectErr, err, socketConnectionResponse.StatusCode)
 		}
-		go ch.startWebSocket()
+		ch.reconnectWebSocket()
 		return
 	}
-	// defer c.Close()
+	ch.mu.Lock()
+	if ch.closed {
+		ch.mu.Unlock()
+		socketConnection.Close()
+		return
+	}
+	ch.getWebSocketBackoff().Reset()
+	ch.webSocketConnected = true
+	ch.webSocketDownSince = time.Time{}
+	ch.socketConnection = socketConnection
+	ch.socketConnectionResponse = socketConnectionResponse
+	reconnected := ch.webSocketReconnecting
+	ch.webSocketReconnecting = false
+	pingInterval, maxSilence := ch.getWebSocketHeartbeat()
+	ch.mu.Unlock()
+	ch.notifyStatusChange()
+	if reconnected {
+		// the notifications sent while the web socket was down are lost, fetch the configurations they were about
+		log.Debug(messages.WebSocketReconnected)
+		ch.getMetricsRecorder().RecordWebSocketReconnect()
+		ch.runInBackground(func() {
+			ch.fetchFromAPIFor(ConfigurationSourceWebSocket)
+		})
+	}
 	done := make(chan struct{})
-	go func() {
+	ch.keepWebSocketAlive(socketConnection, pingInterval, maxSilence, done)
+	ch.runInBackground(func() {
 		defer close(done)
 		for {
-			if ch.socketConnection != nil {
-				_, message, err := ch.socketConnection.ReadMessage()
-				log.Debug(string(message))
-				if err != nil {
-					log.Error(messages.WebsocketErrorReadingMessage, err.Error())
-					go ch.startWebSocket()
+			_, message, err := socketConnection.ReadMessage()
+			log.Debug(string(message))
+			if err != nil {
+				// the connection was closed on purpose, either by close or by a newer connection replacing it
+				if ch.isClosed() || !ch.isCurrentSocketConnection(socketConnection) {
 					return
 				}
-				if string(message) != "test message" {
-					log.Debug(messages.WebsocketReceivingMessage + string(message))
-					ch.fetchFromAPI()
-				}
-			} else {
-				go ch.startWebSocket()
+				log.Error(messages.WebsocketErrorReadingMessage, err.Error())
+				socketConnection.Close()
+				ch.reconnectWebSocket()
 				return
 			}
+			// any message, including the keep alive "test message" of the server, shows the connection is alive
+			socketConnection.SetReadDeadline(time.Now().Add(maxSilence))
+			if string(message) != "test message" {
+				log.Debug(messages.WebsocketReceivingMessage + string(message))
+				ch.requestFetch()
+			}
 		}
-	}()
+	})
 }
-func (ch *ConfigurationHandler) getFeatures() (map[string]models.Feature, error) {
-	if ch.cache == nil {
-		return nil, errors.New(messages.InitError)
+
+// requestFetch : Queue a fetch of the configurations for a web socket notification, without waiting for it. The fetch
+// worker coalesces the notifications received within the debounce window, and those received while it fetches, into
+// a single fetch.
+func (ch *ConfigurationHandler) requestFetch() {
+	ch.mu.Lock()
+	startWorker := ch.fetchRequests == nil
+	if startWorker {
+		ch.fetchRequests = make(chan struct{}, 1)
+	}
+	requests := ch.fetchRequests
+	ch.mu.Unlock()
+	if startWorker {
+		ch.runInBackground(func() {
+			ch.runFetchWorker(requests)
+		})
+	}
+	select {
+	case requests <- struct{}{}:
+	default:
+		log.Debug(messages.WebSocketNotificationCoalesced)
 	}
-	return ch.cache.FeatureMap, nil
 }
-func (ch *ConfigurationHandler) getFeature(featureID string) (models.Feature, error) {
-	if ch.cache != nil && len(ch.cache.FeatureMap) > 0 {
-		if val, ok := ch.cache.FeatureMap[featureID]; ok {
-			return val, nil
+
+// runFetchWorker : Fetch the configurations for the queued requests until the handler is closed
+func (ch *ConfigurationHandler) runFetchWorker(requests chan struct{}) {
+	ctx := ch.getContext()
+	ch.mu.Lock()
+	clock := ch.clock
+	ch.mu.Unlock()
+	if clock == nil {
+		clock = utils.SystemClock
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-requests:
+		}
+		ch.mu.Lock()
+		window := ch.webSocketDebounceWindow
+		ch.mu.Unlock()
+		if window == 0 {
+			window = DefaultWebSocketDebounceWindow
+		}
+		if window > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-clock.After(window):
+			}
+		}
+		// the requests queued during the window are served by this fetch
+		select {
+		case <-requests:
+		default:
 		}
+		ch.fetchFromAPIFor(ConfigurationSourceWebSocket)
 	}
-	log.Error(messages.InvalidFeatureID, featureID)
-	return models.Feature{}, errors.New(messages.ErrorInvalidFeatureID + featureID)
+}
 
+// getWebSocketHeartbeat : Get the interval the web socket is pinged at and the silence after which it is considered stale,
+// the defaults when they are not set. Must be called with mu held.
+func (ch *ConfigurationHandler) getWebSocketHeartbeat() (time.Duration, time.Duration) {
+	pingInterval, maxSilence := ch.webSocketPingInterval, ch.webSocketMaxSilence
+	if pingInterval <= 0 {
+		pingInterval = DefaultWebSocketPingInterval
+	}
+	if maxSilence <= 0 {
+		maxSilence = DefaultWebSocketMaxSilence
+	}
+	return pingInterval, maxSilence
 }
-func (ch *ConfigurationHandler) getProperties() (map[string]models.Property, error) {
-	if ch.cache == nil {
-		return nil, errors.New(messages.InitError)
+
+// keepWebSocketAlive : Ping the server every ping interval until done is closed, and give up reading from the connection
+// when nothing, not even a pong, has been received from the server for max silence. The reader then reconnects.
+func (ch *ConfigurationHandler) keepWebSocketAlive(socketConnection *websocket.Conn, pingInterval, maxSilence time.Duration, done <-chan struct{}) {
+	extendReadDeadline := func() {
+		socketConnection.SetReadDeadline(time.Now().Add(maxSilence))
+	}
+	extendReadDeadline()
+	socketConnection.SetPongHandler(func(string) error {
+		extendReadDeadline()
+		return nil
+	})
+	answerPing := socketConnection.PingHandler()
+	socketConnection.SetPingHandler(func(data string) error {
+		extendReadDeadline()
+		return answerPing(data)
+	})
+	writeWait := pingInterval
+	if writeWait > webSocketWriteWait {
+		writeWait = webSocketWriteWait
 	}
-	return ch.cache.PropertyMap, nil
+	ch.runInBackground(func() {
+		ticker := time.NewTicker(pingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := socketConnection.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+					log.Debug(messages.WebSocketPingError, err)
+				}
+			}
+		}
+	})
 }
-func (ch *ConfigurationHandler) getProperty(propertyID string) (models.Property, error) {
-	if ch.cache != nil && len(ch.cache.PropertyMap) > 0 {
-		if val, ok := ch.cache.PropertyMap[propertyID]; ok {
-			return val, nil
+
+// reconnectWebSocket : Start the web socket again after the delay of the retry policy
+func (ch *ConfigurationHandler) reconnectWebSocket() {
+	ch.setWebSocketConnected(false)
+	ch.mu.Lock()
+	ch.webSocketReconnecting = true
+	backoff := ch.getWebSocketBackoff()
+	delay, _ := backoff.Next()
+	ch.mu.Unlock()
+	ch.runInBackground(func() {
+		if backoff.Wait(ch.getContext(), delay) == nil {
+			ch.startWebSocket()
 		}
+	})
+}
+
+// getWebSocketBackoff : Get the backoff of the web socket connection attempts. Must be called with mu held.
+func (ch *ConfigurationHandler) getWebSocketBackoff() *utils.Backoff {
+	if ch.webSocketBackoff == nil {
+		ch.webSocketBackoff = utils.NewReconnectBackoff(utils.DefaultRetryPolicy(), ch.clock)
 	}
-	log.Error(messages.InvalidPropertyID, propertyID)
-	return models.Property{}, errors.New(messages.ErrorInvalidPropertyID + propertyID)
+	return ch.webSocketBackoff
+}
+
+func (ch *ConfigurationHandler) isCurrentSocketConnection(socketConnection *websocket.Conn) bool {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return ch.socketConnection == socketConnection
+}
+
+// snapshot : Get the current configurations. The snapshot has no configurations until they are loaded.
+func (ch *ConfigurationHandler) snapshot() *Snapshot {
+	return &Snapshot{cache: ch.loadCache()}
+}
+func (ch *ConfigurationHandler) getFeatures() (map[string]models.Feature, error) {
+	return ch.snapshot().GetFeatures()
+}
+func (ch *ConfigurationHandler) getFeature(featureID string) (models.Feature, error) {
+	return ch.snapshot().GetFeature(featureID)
+}
+func (ch *ConfigurationHandler) getProperties() (map[string]models.Property, error) {
+	return ch.snapshot().GetProperties()
+}
+func (ch *ConfigurationHandler) getProperty(propertyID string) (models.Property, error) {
+	return ch.snapshot().GetProperty(propertyID)
 }
 
 func (ch *ConfigurationHandler) registerConfigurationUpdateListener(chl configurationUpdateListenerFunc) {
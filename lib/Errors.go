@@ -0,0 +1,90 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"errors"
+
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/messages"
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/models"
+)
+
+// ErrMissingRegion : Returned by Init when the region is empty
+var ErrMissingRegion = errors.New(messages.RegionError)
+
+// ErrMissingGUID : Returned by Init when the guid is empty
+var ErrMissingGUID = errors.New(messages.GUIDError)
+
+// ErrMissingAPIKey : Returned by Init when the apikey is empty
+var ErrMissingAPIKey = errors.New(messages.ApikeyError)
+
+// ErrMissingAuthenticator : Returned by InitWithAuthenticator when the authenticator is nil
+var ErrMissingAuthenticator = errors.New(messages.AuthenticatorError)
+
+// ErrUnknownRegion : Returned by SetContext when the region is not a supported one and no BaseURL is given
+var ErrUnknownRegion = errors.New(messages.UnknownRegionError)
+
+// ErrInvalidEndpoint : Returned by SetContext when the endpoint is neither ENDPOINT_PUBLIC nor ENDPOINT_PRIVATE
+var ErrInvalidEndpoint = errors.New(messages.EndpointError)
+
+// ErrInvalidBaseURL : Returned by SetContext when the BaseURL or the WebSocketBaseURL is not a valid url of the expected scheme
+var ErrInvalidBaseURL = errors.New(messages.BaseURLError)
+
+// ErrInvalidRefreshMode : Returned by SetContext when the refresh mode is not one of RefreshModeWebSocket, RefreshModePolling or RefreshModeManual
+var ErrInvalidRefreshMode = errors.New(messages.RefreshModeError)
+
+// ErrNotInitialized : Returned when an action needs a successful Init first
+var ErrNotInitialized = errors.New(messages.CollectionIDError)
+
+// ErrMissingCollectionID : Returned by SetContext when the collection id is empty
+var ErrMissingCollectionID = errors.New(messages.CollectionIDValueError)
+
+// ErrMissingEnvironmentID : Returned by SetContext when the environment id is empty
+var ErrMissingEnvironmentID = errors.New(messages.EnvironmentIDValueError)
+
+// ErrBootstrapFileRequired : Returned by SetContext when live config update is disabled and no bootstrap file is given
+var ErrBootstrapFileRequired = errors.New(messages.BootstrapFileNotFoundError)
+
+// ErrIncorrectContextOptions : Returned by SetContext when more than one ContextOptions is passed
+var ErrIncorrectContextOptions = errors.New(messages.IncorrectUsageOfContextOptions)
+
+// ErrConfigFetchFailed : Returned when the configurations could not be fetched from the server
+var ErrConfigFetchFailed = errors.New(messages.ConfigAPIError)
+
+// ErrBootstrapFileUnreadable : Returned when the bootstrap file could not be read
+var ErrBootstrapFileUnreadable = errors.New(messages.BootstrapFileReadError)
+
+// ErrInvalidConfiguration : Returned when the configurations are not a valid json
+var ErrInvalidConfiguration = errors.New(messages.ConfigurationParseError)
+
+// ErrContextNotSet : Returned when an action needs a successful SetContext first
+var ErrContextNotSet = errors.New(messages.CollectionInitError)
+
+// ErrWrongDataType : Returned by the typed value getters when the data type of the feature or property is not the requested one
+var ErrWrongDataType = models.ErrWrongDataType
+
+// ErrWrongDataFormat : Returned by the typed value getters when the data format of the feature or property is not the requested one
+var ErrWrongDataFormat = models.ErrWrongDataFormat
+
+// ErrEvaluationFailed : Returned by the typed value getters when the feature or property could not be evaluated
+var ErrEvaluationFailed = models.ErrEvaluationFailed
+
+// ErrMissingEvaluationContext : Returned by the evaluations taking a context.Context when no EvaluationContext is attached to it
+var ErrMissingEvaluationContext = errors.New(messages.EvaluationContextMissingError)
+
+// ErrConfigurationsNotLoaded : Returned by Snapshot when the configurations have not been loaded yet
+var ErrConfigurationsNotLoaded = errors.New(messages.InitError)
@@ -0,0 +1,96 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"context"
+	"time"
+)
+
+// EvaluationContext : Entity a feature or property is evaluated for, made of the entity id and its attributes.
+// An EvaluationContext is immutable, the With methods return a copy with the attribute added, so it is safe to share it
+// between goroutines and to derive contexts from a common one.
+type EvaluationContext struct {
+	entityID   string
+	attributes map[string]interface{}
+}
+
+// evaluationContextKey : Key of the EvaluationContext in a context.Context
+type evaluationContextKey struct{}
+
+// NewEvaluationContext : Create an evaluation context for the entity with the given id
+func NewEvaluationContext(entityID string) EvaluationContext {
+	return EvaluationContext{entityID: entityID}
+}
+
+// GetEntityID : Get the entity id
+func (ec EvaluationContext) GetEntityID() string {
+	return ec.entityID
+}
+
+// GetAttributes : Get a copy of the entity attributes, as passed to the evaluations
+func (ec EvaluationContext) GetAttributes() map[string]interface{} {
+	attributes := make(map[string]interface{}, len(ec.attributes))
+	for name, value := range ec.attributes {
+		attributes[name] = value
+	}
+	return attributes
+}
+
+// WithString : Add a string attribute
+func (ec EvaluationContext) WithString(name string, value string) EvaluationContext {
+	return ec.with(name, value)
+}
+
+// WithNumber : Add a numeric attribute
+func (ec EvaluationContext) WithNumber(name string, value float64) EvaluationContext {
+	return ec.with(name, value)
+}
+
+// WithBool : Add a boolean attribute
+func (ec EvaluationContext) WithBool(name string, value bool) EvaluationContext {
+	return ec.with(name, value)
+}
+
+// WithTime : Add a time attribute. It is evaluated as the number of milliseconds since the Unix epoch, so segment rules
+// compare it with the greaterThan, lesserThan, greaterThanEquals and lesserThanEquals operators.
+func (ec EvaluationContext) WithTime(name string, value time.Time) EvaluationContext {
+	return ec.with(name, float64(value.UnixMilli()))
+}
+
+// WithStringList : Add a list of strings attribute. A segment rule matches it when it matches any of the strings.
+func (ec EvaluationContext) WithStringList(name string, value []string) EvaluationContext {
+	return ec.with(name, append([]string(nil), value...))
+}
+
+func (ec EvaluationContext) with(name string, value interface{}) EvaluationContext {
+	attributes := ec.GetAttributes()
+	attributes[name] = value
+	return EvaluationContext{entityID: ec.entityID, attributes: attributes}
+}
+
+// ContextWithEvaluationContext : Attach the evaluation context to ctx, so that it is used by the evaluations taking a
+// context.Context, such as AppConfiguration.BoolFlag
+func ContextWithEvaluationContext(ctx context.Context, ec EvaluationContext) context.Context {
+	return context.WithValue(ctx, evaluationContextKey{}, ec)
+}
+
+// EvaluationContextFromContext : Get the evaluation context attached to ctx
+func EvaluationContextFromContext(ctx context.Context) (EvaluationContext, bool) {
+	ec, ok := ctx.Value(evaluationContextKey{}).(EvaluationContext)
+	return ec, ok
+}
@@ -0,0 +1,50 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	models "github.com/IBM/appconfiguration-go-sdk/lib/internal/models"
+)
+
+// EvaluationDetails : Value of an evaluation together with the reason for it. See models.Feature.EvaluateDetailed
+type EvaluationDetails = models.EvaluationDetails
+
+// SegmentEvaluation : Result of evaluating a segment against the entity attributes
+type SegmentEvaluation = models.SegmentEvaluation
+
+// RuleEvaluation : Result of evaluating one rule of a segment against the entity attributes
+type RuleEvaluation = models.RuleEvaluation
+
+// EvaluationReason : Reason for the value of an evaluation
+type EvaluationReason = models.EvaluationReason
+
+const (
+	// EvaluationReasonDisabled : The feature is disabled
+	EvaluationReasonDisabled = models.EvaluationReasonDisabled
+	// EvaluationReasonDefaultNoRules : The feature or property has no segment rules
+	EvaluationReasonDefaultNoRules = models.EvaluationReasonDefaultNoRules
+	// EvaluationReasonSegmentMatch : The entity belongs to a segment with an overridden value
+	EvaluationReasonSegmentMatch = models.EvaluationReasonSegmentMatch
+	// EvaluationReasonSegmentMatchDefaultValue : The entity belongs to a segment that uses the default value
+	EvaluationReasonSegmentMatchDefaultValue = models.EvaluationReasonSegmentMatchDefaultValue
+	// EvaluationReasonNoSegmentMatched : The entity belongs to none of the segments
+	EvaluationReasonNoSegmentMatched = models.EvaluationReasonNoSegmentMatched
+	// EvaluationReasonInvalid : The entity id is empty or the feature or property is incomplete
+	EvaluationReasonInvalid = models.EvaluationReasonInvalid
+	// EvaluationReasonTypeError : The value does not match the data type and format of the feature or property
+	EvaluationReasonTypeError = models.EvaluationReasonTypeError
+)
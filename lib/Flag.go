@@ -0,0 +1,192 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"context"
+	"fmt"
+)
+
+// valueGetter : Typed value getters shared by models.Feature and models.Property
+type valueGetter interface {
+	GetBoolValue(entityID string, entityAttributes map[string]interface{}, defaultValue bool) (bool, error)
+	GetStringValue(entityID string, entityAttributes map[string]interface{}, defaultValue string) (string, error)
+	GetFloat64Value(entityID string, entityAttributes map[string]interface{}, defaultValue float64) (float64, error)
+	GetIntValue(entityID string, entityAttributes map[string]interface{}, defaultValue int) (int, error)
+	GetJSONValue(entityID string, entityAttributes map[string]interface{}, into interface{}) error
+	GetYAMLValue(entityID string, entityAttributes map[string]interface{}, into interface{}) error
+}
+
+// Flag : Handle to the feature flag with the given id, decoding its value into T.
+// The feature is looked up in the cache of the client on every evaluation, so a handle can be created once and kept.
+type Flag[T any] struct {
+	client       *AppConfiguration
+	featureID    string
+	defaultValue T
+}
+
+// Prop : Handle to the property with the given id, decoding its value into T.
+// The property is looked up in the cache of the client on every evaluation, so a handle can be created once and kept.
+type Prop[T any] struct {
+	client       *AppConfiguration
+	propertyID   string
+	defaultValue T
+}
+
+// NewFlag : Create a handle to a feature flag. T is bool for BOOLEAN, float64 or int for NUMERIC, string for TEXT and
+// any type the value can be decoded into for JSON and YAML features.
+func NewFlag[T any](client *AppConfiguration, featureID string, defaultValue T) *Flag[T] {
+	return &Flag[T]{
+		client:       client,
+		featureID:    featureID,
+		defaultValue: defaultValue,
+	}
+}
+
+// BoolFlag : Create a handle to a BOOLEAN feature flag
+func BoolFlag(client *AppConfiguration, featureID string, defaultValue bool) *Flag[bool] {
+	return NewFlag(client, featureID, defaultValue)
+}
+
+// StringFlag : Create a handle to a STRING feature flag of TEXT format
+func StringFlag(client *AppConfiguration, featureID string, defaultValue string) *Flag[string] {
+	return NewFlag(client, featureID, defaultValue)
+}
+
+// Float64Flag : Create a handle to a NUMERIC feature flag
+func Float64Flag(client *AppConfiguration, featureID string, defaultValue float64) *Flag[float64] {
+	return NewFlag(client, featureID, defaultValue)
+}
+
+// IntFlag : Create a handle to a NUMERIC feature flag holding whole numbers
+func IntFlag(client *AppConfiguration, featureID string, defaultValue int) *Flag[int] {
+	return NewFlag(client, featureID, defaultValue)
+}
+
+// GetFeatureID : Get the id of the feature flag
+func (fl *Flag[T]) GetFeatureID() string {
+	return fl.featureID
+}
+
+// Evaluate : Evaluate the feature flag for the entity. The default value is returned together with an error when the
+// feature does not exist, can't be evaluated or its data type or format does not match T.
+func (fl *Flag[T]) Evaluate(entityID string, entityAttributes map[string]interface{}) (T, error) {
+	feature, err := fl.client.GetFeature(fl.featureID)
+	if err != nil {
+		return fl.defaultValue, err
+	}
+	return evaluateAs(&feature, feature.GetFeatureDataType(), feature.GetFeatureDataFormat(), entityID, entityAttributes, fl.defaultValue)
+}
+
+// EvaluateContext : Evaluate the feature flag for the EvaluationContext attached to ctx. See Evaluate
+func (fl *Flag[T]) EvaluateContext(ctx context.Context) (T, error) {
+	ec, ok := EvaluationContextFromContext(ctx)
+	if !ok {
+		return fl.defaultValue, ErrMissingEvaluationContext
+	}
+	return fl.Evaluate(ec.entityID, ec.attributes)
+}
+
+// NewProp : Create a handle to a property. T is bool for BOOLEAN, float64 or int for NUMERIC, string for TEXT and
+// any type the value can be decoded into for JSON and YAML properties.
+func NewProp[T any](client *AppConfiguration, propertyID string, defaultValue T) *Prop[T] {
+	return &Prop[T]{
+		client:       client,
+		propertyID:   propertyID,
+		defaultValue: defaultValue,
+	}
+}
+
+// BoolProp : Create a handle to a BOOLEAN property
+func BoolProp(client *AppConfiguration, propertyID string, defaultValue bool) *Prop[bool] {
+	return NewProp(client, propertyID, defaultValue)
+}
+
+// StringProp : Create a handle to a STRING property of TEXT format
+func StringProp(client *AppConfiguration, propertyID string, defaultValue string) *Prop[string] {
+	return NewProp(client, propertyID, defaultValue)
+}
+
+// Float64Prop : Create a handle to a NUMERIC property
+func Float64Prop(client *AppConfiguration, propertyID string, defaultValue float64) *Prop[float64] {
+	return NewProp(client, propertyID, defaultValue)
+}
+
+// IntProp : Create a handle to a NUMERIC property holding whole numbers
+func IntProp(client *AppConfiguration, propertyID string, defaultValue int) *Prop[int] {
+	return NewProp(client, propertyID, defaultValue)
+}
+
+// GetPropertyID : Get the id of the property
+func (pr *Prop[T]) GetPropertyID() string {
+	return pr.propertyID
+}
+
+// Evaluate : Evaluate the property for the entity. The default value is returned together with an error when the
+// property does not exist, can't be evaluated or its data type or format does not match T.
+func (pr *Prop[T]) Evaluate(entityID string, entityAttributes map[string]interface{}) (T, error) {
+	property, err := pr.client.GetProperty(pr.propertyID)
+	if err != nil {
+		return pr.defaultValue, err
+	}
+	return evaluateAs(&property, property.GetPropertyDataType(), property.GetPropertyDataFormat(), entityID, entityAttributes, pr.defaultValue)
+}
+
+// EvaluateContext : Evaluate the property for the EvaluationContext attached to ctx. See Evaluate
+func (pr *Prop[T]) EvaluateContext(ctx context.Context) (T, error) {
+	ec, ok := EvaluationContextFromContext(ctx)
+	if !ok {
+		return pr.defaultValue, ErrMissingEvaluationContext
+	}
+	return pr.Evaluate(ec.entityID, ec.attributes)
+}
+
+// evaluateAs : Evaluate with the typed getter matching T. Types other than bool, string, float64 and int are decoded
+// from JSON or YAML values, and ErrWrongDataFormat is returned for the TEXT ones.
+func evaluateAs[T any](getter valueGetter, dataType string, dataFormat string, entityID string, entityAttributes map[string]interface{}, defaultValue T) (T, error) {
+	var err error
+	// decoding starts from the zero value, so that maps or pointers in the default value are never modified
+	var value T
+	switch into := any(&value).(type) {
+	case *bool:
+		*into, err = getter.GetBoolValue(entityID, entityAttributes, *into)
+	case *string:
+		*into, err = getter.GetStringValue(entityID, entityAttributes, *into)
+	case *float64:
+		*into, err = getter.GetFloat64Value(entityID, entityAttributes, *into)
+	case *int:
+		*into, err = getter.GetIntValue(entityID, entityAttributes, *into)
+	default:
+		switch dataFormat {
+		case "JSON":
+			err = getter.GetJSONValue(entityID, entityAttributes, into)
+		case "YAML":
+			err = getter.GetYAMLValue(entityID, entityAttributes, into)
+		default:
+			if dataType == "STRING" {
+				// a TEXT value is of the right type, only its format can not be decoded
+				err = fmt.Errorf("%w: %s %s can not be decoded into %T", ErrWrongDataFormat, dataType, dataFormat, value)
+			} else {
+				err = fmt.Errorf("%w: %s %s can not be decoded into %T", ErrWrongDataType, dataType, dataFormat, value)
+			}
+		}
+	}
+	if err != nil {
+		return defaultValue, err
+	}
+	return value, nil
+}
@@ -0,0 +1,28 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils"
+)
+
+// MetricsRecorder : Receives the measurements of a client: the evaluations of the features and properties with the
+// segment that matched, the attempts to fetch the configurations with their duration and error, the websocket
+// reconnections, the size of the configurations in use, the time the configuration update listeners take and the
+// batches of usage data sent to the server or dropped. The methods must be safe for concurrent use and return quickly.
+// The github.com/IBM/appconfiguration-go-sdk/lib/metrics module provides Prometheus and OpenTelemetry recorders.
+type MetricsRecorder = utils.MetricsRecorder
@@ -0,0 +1,118 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"context"
+	"time"
+
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/messages"
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils"
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
+)
+
+// RefreshMode : How the configurations are kept up to date with the server once they are loaded
+type RefreshMode string
+
+const (
+	// RefreshModeWebSocket : Fetch the configurations every time the server notifies a change on the websocket (the default)
+	RefreshModeWebSocket RefreshMode = "websocket"
+	// RefreshModePolling : Fetch the configurations at a regular interval, without a websocket
+	RefreshModePolling RefreshMode = "polling"
+	// RefreshModeManual : Fetch the configurations only when FetchConfigurations is called
+	RefreshModeManual RefreshMode = "manual"
+)
+
+// DefaultPollingInterval : Interval the configurations are polled at when none is given
+const DefaultPollingInterval = 5 * time.Minute
+
+// DefaultPollingJitter : Fraction of the polling interval it is randomised by when no jitter is given
+const DefaultPollingJitter = 0.1
+
+// isValid : Check that the refresh mode is one of the known ones, the empty one being the default
+func (rm RefreshMode) isValid() bool {
+	switch rm {
+	case "", RefreshModeWebSocket, RefreshModePolling, RefreshModeManual:
+		return true
+	}
+	return false
+}
+
+// startPolling : Start fetching the configurations every polling interval, unless polling already runs. In the websocket
+// refresh mode, the configurations are only fetched while the websocket has been disconnected for longer than the
+// fallback threshold.
+func (ch *ConfigurationHandler) startPolling() {
+	ch.mu.Lock()
+	if ch.stopPolling != nil || ch.closed {
+		ch.mu.Unlock()
+		return
+	}
+	parent := ch.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	ch.stopPolling = cancel
+	interval, jitter := ch.pollingInterval, ch.pollingJitter
+	refreshMode, fallbackThreshold := ch.refreshMode, ch.webSocketFallbackThreshold
+	if refreshMode == RefreshModeWebSocket && !ch.webSocketConnected && ch.webSocketDownSince.IsZero() {
+		ch.webSocketDownSince = time.Now()
+	}
+	clock := ch.clock
+	ch.mu.Unlock()
+	if interval <= 0 {
+		interval = DefaultPollingInterval
+	}
+	if clock == nil {
+		clock = utils.SystemClock
+	}
+	log.Debug(messages.PollingStarted, interval)
+	ch.runInBackground(func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-clock.After(utils.Jitter(interval, jitter)):
+			}
+			if refreshMode == RefreshModeWebSocket && !ch.isWebSocketDownFor(fallbackThreshold) {
+				continue
+			}
+			log.Debug(messages.PollingConfigurations)
+			ch.fetchFromAPIFor(ConfigurationSourcePolling)
+		}
+	})
+}
+
+// isWebSocketDownFor : Check whether the websocket has been disconnected for longer than d
+func (ch *ConfigurationHandler) isWebSocketDownFor(d time.Duration) bool {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return !ch.webSocketConnected && !ch.webSocketDownSince.IsZero() && time.Since(ch.webSocketDownSince) > d
+}
+
+// setWebSocketConnected : Record that the websocket got connected or disconnected
+func (ch *ConfigurationHandler) setWebSocketConnected(connected bool) {
+	ch.mu.Lock()
+	if connected {
+		ch.webSocketDownSince = time.Time{}
+	} else if ch.webSocketConnected || ch.webSocketDownSince.IsZero() {
+		ch.webSocketDownSince = time.Now()
+	}
+	ch.webSocketConnected = connected
+	ch.mu.Unlock()
+	ch.notifyStatusChange()
+}
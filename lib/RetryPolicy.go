@@ -0,0 +1,33 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils"
+)
+
+// RetryPolicy : Delays between the attempts of a request to the server that fails. The delay before the second attempt
+// is InitialDelay, and every following delay is Multiplier times the previous one, up to MaxDelay. Each delay is then
+// randomised by up to Jitter (a fraction between 0 and 1) of it, in either direction. After MaxAttempts attempts the
+// request is given up until the next retry cycle, which starts after MaxDelay.
+type RetryPolicy = utils.RetryPolicy
+
+// DefaultRetryPolicy : Retry policy used when ContextOptions has none: 1s initial delay doubling up to 10 minutes,
+// 20% jitter and 3 attempts
+func DefaultRetryPolicy() RetryPolicy {
+	return utils.DefaultRetryPolicy()
+}
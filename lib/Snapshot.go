@@ -0,0 +1,84 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"errors"
+
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/messages"
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/models"
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
+)
+
+// Snapshot : Configurations loaded by one update, which later updates leave untouched. The features and properties of
+// a snapshot, like all the ones returned by the client, evaluate segments from the configurations they were read from,
+// so evaluating several of them from one snapshot gives results consistent with a single configuration version.
+type Snapshot struct {
+	cache *models.Cache
+}
+
+// GetFeature : Get the feature with the given id
+func (s *Snapshot) GetFeature(featureID string) (models.Feature, error) {
+	if s.cache == nil {
+		return models.Feature{}, ErrConfigurationsNotLoaded
+	}
+	if len(s.cache.FeatureMap) > 0 {
+		if val, ok := s.cache.FeatureMap[featureID]; ok {
+			return val, nil
+		}
+	}
+	log.Error(messages.InvalidFeatureID, featureID)
+	return models.Feature{}, errors.New(messages.ErrorInvalidFeatureID + featureID)
+}
+
+// GetFeatures : Get a copy of the features, which the caller is free to modify
+func (s *Snapshot) GetFeatures() (map[string]models.Feature, error) {
+	if s.cache == nil {
+		return nil, ErrConfigurationsNotLoaded
+	}
+	features := make(map[string]models.Feature, len(s.cache.FeatureMap))
+	for featureID, feature := range s.cache.FeatureMap {
+		features[featureID] = feature
+	}
+	return features, nil
+}
+
+// GetProperty : Get the property with the given id
+func (s *Snapshot) GetProperty(propertyID string) (models.Property, error) {
+	if s.cache == nil {
+		return models.Property{}, ErrConfigurationsNotLoaded
+	}
+	if len(s.cache.PropertyMap) > 0 {
+		if val, ok := s.cache.PropertyMap[propertyID]; ok {
+			return val, nil
+		}
+	}
+	log.Error(messages.InvalidPropertyID, propertyID)
+	return models.Property{}, errors.New(messages.ErrorInvalidPropertyID + propertyID)
+}
+
+// GetProperties : Get a copy of the properties, which the caller is free to modify
+func (s *Snapshot) GetProperties() (map[string]models.Property, error) {
+	if s.cache == nil {
+		return nil, ErrConfigurationsNotLoaded
+	}
+	properties := make(map[string]models.Property, len(s.cache.PropertyMap))
+	for propertyID, property := range s.cache.PropertyMap {
+		properties[propertyID] = property
+	}
+	return properties, nil
+}
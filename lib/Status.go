@@ -0,0 +1,154 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"time"
+
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/messages"
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
+)
+
+// StatusChangeListener : Called with the new status every time the status of the client changes
+type StatusChangeListener func(Status)
+
+// Status : Health of the connection to the server and of the configurations in use
+type Status struct {
+	// WebSocketConnected : Whether the websocket notifying the configuration changes is connected
+	WebSocketConnected bool
+	// LastSuccessfulFetch : When the configurations were last fetched from the server, zero when they never were
+	LastSuccessfulFetch time.Time
+	// LastError : Last error fetching the configurations, nil when there was none. A later successful fetch does not reset it.
+	LastError error
+	// LastErrorTime : When LastError happened
+	LastErrorTime time.Time
+	// ConsecutiveFailures : Number of failed attempts to fetch the configurations since the last successful one
+	ConsecutiveFailures int
+	// Source : Source of the configurations in use, empty until they are loaded
+	Source ConfigurationSource
+	// Version : Checksum of the configurations in use, which changes every time they do
+	Version string
+	// ETag : ETag the server returned with the configurations in use, empty when they were not fetched with one
+	ETag string
+}
+
+// equal : Check whether two statuses are the same, errors being compared by their message
+func (s Status) equal(other Status) bool {
+	errorMessage := func(err error) string {
+		if err == nil {
+			return ""
+		}
+		return err.Error()
+	}
+	return s.WebSocketConnected == other.WebSocketConnected &&
+		s.LastSuccessfulFetch.Equal(other.LastSuccessfulFetch) &&
+		(s.LastError == nil) == (other.LastError == nil) &&
+		errorMessage(s.LastError) == errorMessage(other.LastError) &&
+		s.LastErrorTime.Equal(other.LastErrorTime) &&
+		s.ConsecutiveFailures == other.ConsecutiveFailures &&
+		s.Source == other.Source &&
+		s.Version == other.Version &&
+		s.ETag == other.ETag
+}
+
+type statusChangeListener struct {
+	id       uint64
+	listener StatusChangeListener
+}
+
+// status : Get the current status
+func (ch *ConfigurationHandler) status() Status {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	status := Status{
+		WebSocketConnected:  ch.webSocketConnected && !ch.closed,
+		LastSuccessfulFetch: ch.lastSuccessfulFetch,
+		LastError:           ch.lastError,
+		LastErrorTime:       ch.lastErrorTime,
+		ConsecutiveFailures: ch.consecutiveFailures,
+	}
+	if ch.loadCache() != nil {
+		status.Source = ch.cacheSource
+		status.Version = ch.checksum
+		status.ETag = ch.etag
+	}
+	return status
+}
+
+// recordFetchSuccess : Record that the configurations were fetched from the server
+func (ch *ConfigurationHandler) recordFetchSuccess() {
+	ch.mu.Lock()
+	ch.lastSuccessfulFetch = time.Now()
+	ch.consecutiveFailures = 0
+	ch.mu.Unlock()
+	ch.notifyStatusChange()
+}
+
+// recordFetchFailure : Record that an attempt to fetch the configurations from the server failed with err
+func (ch *ConfigurationHandler) recordFetchFailure(err error) {
+	ch.mu.Lock()
+	ch.lastError = err
+	ch.lastErrorTime = time.Now()
+	ch.consecutiveFailures++
+	ch.mu.Unlock()
+	ch.notifyStatusChange()
+}
+
+// notifyStatusChange : Call the status change listeners when the status differs from the one they were last called
+// with. Must be called without mu held. The listeners are called without any lock held, so that they can use the client.
+func (ch *ConfigurationHandler) notifyStatusChange() {
+	ch.statusMu.Lock()
+	status := ch.status()
+	if status.equal(ch.notifiedStatus) {
+		ch.statusMu.Unlock()
+		return
+	}
+	ch.notifiedStatus = status
+	ch.statusListenersMu.Lock()
+	listeners := append([]statusChangeListener(nil), ch.statusListeners...)
+	ch.statusListenersMu.Unlock()
+	ch.statusMu.Unlock()
+	for _, l := range listeners {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Error(messages.StatusChangeListenerError, r)
+				}
+			}()
+			l.listener(status)
+		}()
+	}
+}
+
+// addStatusChangeListener : Add a status change listener and return the function removing it
+func (ch *ConfigurationHandler) addStatusChangeListener(listener StatusChangeListener) func() {
+	ch.statusListenersMu.Lock()
+	defer ch.statusListenersMu.Unlock()
+	ch.nextStatusListenerID++
+	id := ch.nextStatusListenerID
+	ch.statusListeners = append(ch.statusListeners, statusChangeListener{id: id, listener: listener})
+	return func() {
+		ch.statusListenersMu.Lock()
+		defer ch.statusListenersMu.Unlock()
+		for i, l := range ch.statusListeners {
+			if l.id == id {
+				ch.statusListeners = append(ch.statusListeners[:i:i], ch.statusListeners[i+1:]...)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,205 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"context"
+)
+
+// GetFeatureBoolValue : Get the current value of the BOOLEAN feature with the given id. See models.Feature.GetBoolValue
+func (ac *AppConfiguration) GetFeatureBoolValue(featureID string, entityID string, entityAttributes map[string]interface{}, defaultValue bool) (bool, error) {
+	feature, err := ac.GetFeature(featureID)
+	if err != nil {
+		return defaultValue, err
+	}
+	return feature.GetBoolValue(entityID, entityAttributes, defaultValue)
+}
+
+// GetFeatureStringValue : Get the current value of the STRING feature of TEXT format with the given id. See models.Feature.GetStringValue
+func (ac *AppConfiguration) GetFeatureStringValue(featureID string, entityID string, entityAttributes map[string]interface{}, defaultValue string) (string, error) {
+	feature, err := ac.GetFeature(featureID)
+	if err != nil {
+		return defaultValue, err
+	}
+	return feature.GetStringValue(entityID, entityAttributes, defaultValue)
+}
+
+// GetFeatureFloat64Value : Get the current value of the NUMERIC feature with the given id. See models.Feature.GetFloat64Value
+func (ac *AppConfiguration) GetFeatureFloat64Value(featureID string, entityID string, entityAttributes map[string]interface{}, defaultValue float64) (float64, error) {
+	feature, err := ac.GetFeature(featureID)
+	if err != nil {
+		return defaultValue, err
+	}
+	return feature.GetFloat64Value(entityID, entityAttributes, defaultValue)
+}
+
+// GetFeatureIntValue : Get the current value of the NUMERIC feature with the given id. See models.Feature.GetIntValue
+func (ac *AppConfiguration) GetFeatureIntValue(featureID string, entityID string, entityAttributes map[string]interface{}, defaultValue int) (int, error) {
+	feature, err := ac.GetFeature(featureID)
+	if err != nil {
+		return defaultValue, err
+	}
+	return feature.GetIntValue(entityID, entityAttributes, defaultValue)
+}
+
+// GetFeatureJSONValue : Decode the current value of the feature with the given id into the value pointed to by into. See models.Feature.GetJSONValue
+func (ac *AppConfiguration) GetFeatureJSONValue(featureID string, entityID string, entityAttributes map[string]interface{}, into interface{}) error {
+	feature, err := ac.GetFeature(featureID)
+	if err != nil {
+		return err
+	}
+	return feature.GetJSONValue(entityID, entityAttributes, into)
+}
+
+// GetFeatureYAMLValue : Decode the current value of the feature with the given id into the value pointed to by into. See models.Feature.GetYAMLValue
+func (ac *AppConfiguration) GetFeatureYAMLValue(featureID string, entityID string, entityAttributes map[string]interface{}, into interface{}) error {
+	feature, err := ac.GetFeature(featureID)
+	if err != nil {
+		return err
+	}
+	return feature.GetYAMLValue(entityID, entityAttributes, into)
+}
+
+// GetPropertyBoolValue : Get the current value of the BOOLEAN property with the given id. See models.Property.GetBoolValue
+func (ac *AppConfiguration) GetPropertyBoolValue(propertyID string, entityID string, entityAttributes map[string]interface{}, defaultValue bool) (bool, error) {
+	property, err := ac.GetProperty(propertyID)
+	if err != nil {
+		return defaultValue, err
+	}
+	return property.GetBoolValue(entityID, entityAttributes, defaultValue)
+}
+
+// GetPropertyStringValue : Get the current value of the STRING property of TEXT format with the given id. See models.Property.GetStringValue
+func (ac *AppConfiguration) GetPropertyStringValue(propertyID string, entityID string, entityAttributes map[string]interface{}, defaultValue string) (string, error) {
+	property, err := ac.GetProperty(propertyID)
+	if err != nil {
+		return defaultValue, err
+	}
+	return property.GetStringValue(entityID, entityAttributes, defaultValue)
+}
+
+// GetPropertyFloat64Value : Get the current value of the NUMERIC property with the given id. See models.Property.GetFloat64Value
+func (ac *AppConfiguration) GetPropertyFloat64Value(propertyID string, entityID string, entityAttributes map[string]interface{}, defaultValue float64) (float64, error) {
+	property, err := ac.GetProperty(propertyID)
+	if err != nil {
+		return defaultValue, err
+	}
+	return property.GetFloat64Value(entityID, entityAttributes, defaultValue)
+}
+
+// GetPropertyIntValue : Get the current value of the NUMERIC property with the given id. See models.Property.GetIntValue
+func (ac *AppConfiguration) GetPropertyIntValue(propertyID string, entityID string, entityAttributes map[string]interface{}, defaultValue int) (int, error) {
+	property, err := ac.GetProperty(propertyID)
+	if err != nil {
+		return defaultValue, err
+	}
+	return property.GetIntValue(entityID, entityAttributes, defaultValue)
+}
+
+// GetPropertyJSONValue : Decode the current value of the property with the given id into the value pointed to by into. See models.Property.GetJSONValue
+func (ac *AppConfiguration) GetPropertyJSONValue(propertyID string, entityID string, entityAttributes map[string]interface{}, into interface{}) error {
+	property, err := ac.GetProperty(propertyID)
+	if err != nil {
+		return err
+	}
+	return property.GetJSONValue(entityID, entityAttributes, into)
+}
+
+// GetPropertyYAMLValue : Decode the current value of the property with the given id into the value pointed to by into. See models.Property.GetYAMLValue
+func (ac *AppConfiguration) GetPropertyYAMLValue(propertyID string, entityID string, entityAttributes map[string]interface{}, into interface{}) error {
+	property, err := ac.GetProperty(propertyID)
+	if err != nil {
+		return err
+	}
+	return property.GetYAMLValue(entityID, entityAttributes, into)
+}
+
+// BoolFlag : Get the current value of the BOOLEAN feature with the given id for the EvaluationContext attached to ctx
+func (ac *AppConfiguration) BoolFlag(ctx context.Context, featureID string, defaultValue bool) (bool, error) {
+	return NewFlag(ac, featureID, defaultValue).EvaluateContext(ctx)
+}
+
+// StringFlag : Get the current value of the STRING feature of TEXT format with the given id for the EvaluationContext attached to ctx
+func (ac *AppConfiguration) StringFlag(ctx context.Context, featureID string, defaultValue string) (string, error) {
+	return NewFlag(ac, featureID, defaultValue).EvaluateContext(ctx)
+}
+
+// Float64Flag : Get the current value of the NUMERIC feature with the given id for the EvaluationContext attached to ctx
+func (ac *AppConfiguration) Float64Flag(ctx context.Context, featureID string, defaultValue float64) (float64, error) {
+	return NewFlag(ac, featureID, defaultValue).EvaluateContext(ctx)
+}
+
+// IntFlag : Get the current value of the NUMERIC feature with the given id for the EvaluationContext attached to ctx
+func (ac *AppConfiguration) IntFlag(ctx context.Context, featureID string, defaultValue int) (int, error) {
+	return NewFlag(ac, featureID, defaultValue).EvaluateContext(ctx)
+}
+
+// JSONFlag : Decode the current value of the feature with the given id for the EvaluationContext attached to ctx into the value pointed to by into
+func (ac *AppConfiguration) JSONFlag(ctx context.Context, featureID string, into interface{}) error {
+	ec, ok := EvaluationContextFromContext(ctx)
+	if !ok {
+		return ErrMissingEvaluationContext
+	}
+	return ac.GetFeatureJSONValue(featureID, ec.entityID, ec.attributes, into)
+}
+
+// YAMLFlag : Decode the current value of the feature with the given id for the EvaluationContext attached to ctx into the value pointed to by into
+func (ac *AppConfiguration) YAMLFlag(ctx context.Context, featureID string, into interface{}) error {
+	ec, ok := EvaluationContextFromContext(ctx)
+	if !ok {
+		return ErrMissingEvaluationContext
+	}
+	return ac.GetFeatureYAMLValue(featureID, ec.entityID, ec.attributes, into)
+}
+
+// BoolProp : Get the current value of the BOOLEAN property with the given id for the EvaluationContext attached to ctx
+func (ac *AppConfiguration) BoolProp(ctx context.Context, propertyID string, defaultValue bool) (bool, error) {
+	return NewProp(ac, propertyID, defaultValue).EvaluateContext(ctx)
+}
+
+// StringProp : Get the current value of the STRING property of TEXT format with the given id for the EvaluationContext attached to ctx
+func (ac *AppConfiguration) StringProp(ctx context.Context, propertyID string, defaultValue string) (string, error) {
+	return NewProp(ac, propertyID, defaultValue).EvaluateContext(ctx)
+}
+
+// Float64Prop : Get the current value of the NUMERIC property with the given id for the EvaluationContext attached to ctx
+func (ac *AppConfiguration) Float64Prop(ctx context.Context, propertyID string, defaultValue float64) (float64, error) {
+	return NewProp(ac, propertyID, defaultValue).EvaluateContext(ctx)
+}
+
+// IntProp : Get the current value of the NUMERIC property with the given id for the EvaluationContext attached to ctx
+func (ac *AppConfiguration) IntProp(ctx context.Context, propertyID string, defaultValue int) (int, error) {
+	return NewProp(ac, propertyID, defaultValue).EvaluateContext(ctx)
+}
+
+// JSONProp : Decode the current value of the property with the given id for the EvaluationContext attached to ctx into the value pointed to by into
+func (ac *AppConfiguration) JSONProp(ctx context.Context, propertyID string, into interface{}) error {
+	ec, ok := EvaluationContextFromContext(ctx)
+	if !ok {
+		return ErrMissingEvaluationContext
+	}
+	return ac.GetPropertyJSONValue(propertyID, ec.entityID, ec.attributes, into)
+}
+
+// YAMLProp : Decode the current value of the property with the given id for the EvaluationContext attached to ctx into the value pointed to by into
+func (ac *AppConfiguration) YAMLProp(ctx context.Context, propertyID string, into interface{}) error {
+	ec, ok := EvaluationContextFromContext(ctx)
+	if !ok {
+		return ErrMissingEvaluationContext
+	}
+	return ac.GetPropertyYAMLValue(propertyID, ec.entityID, ec.attributes, into)
+}
@@ -0,0 +1,99 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"sync"
+)
+
+// watch : Channel of a watcher of one feature or property. It has room for one value, which is replaced by newer ones
+// when the watcher is slow to receive, so that sending never blocks.
+type watch[T any] struct {
+	values chan T
+}
+
+// watchRegistry : Watchers of the features or properties, by id. The zero value is ready to use.
+type watchRegistry[T any] struct {
+	watches map[string]map[*watch[T]]struct{}
+	mu      sync.Mutex
+}
+
+func (r *watchRegistry[T]) add(id string) *watch[T] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.watches == nil {
+		r.watches = make(map[string]map[*watch[T]]struct{})
+	}
+	if r.watches[id] == nil {
+		r.watches[id] = make(map[*watch[T]]struct{})
+	}
+	w := &watch[T]{values: make(chan T, 1)}
+	r.watches[id][w] = struct{}{}
+	return w
+}
+
+// remove : Remove the watcher and close its channel. Removing a watcher twice does nothing.
+func (r *watchRegistry[T]) remove(id string, w *watch[T]) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.watches[id][w]; !ok {
+		return
+	}
+	delete(r.watches[id], w)
+	if len(r.watches[id]) == 0 {
+		delete(r.watches, id)
+	}
+	close(w.values)
+}
+
+// notify : Send the value to the watchers of the id, replacing the value they have not received yet
+func (r *watchRegistry[T]) notify(id string, value T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for w := range r.watches[id] {
+		w.send(value)
+	}
+}
+
+// closeAll : Remove all the watchers and close their channels
+func (r *watchRegistry[T]) closeAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, watches := range r.watches {
+		for w := range watches {
+			close(w.values)
+		}
+	}
+	r.watches = nil
+}
+
+func (w *watch[T]) send(value T) {
+	select {
+	case w.values <- value:
+		return
+	default:
+	}
+	// drop the value not received yet and keep the latest one only
+	select {
+	case <-w.values:
+	default:
+	}
+	select {
+	case w.values <- value:
+	default:
+	}
+}
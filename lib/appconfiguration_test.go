@@ -17,9 +17,23 @@
 package lib
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"runtime"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/models"
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils"
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/gorilla/websocket"
 	// "github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
 
 	"github.com/stretchr/testify/assert"
@@ -35,7 +49,8 @@ func TestInit(t *testing.T) {
 	// test get feature when not initialised properly
 	mockLogger()
 	ac := GetInstance()
-	ac.Init("", "", "")
+	err := ac.Init("", "", "")
+	assert.True(t, errors.Is(err, ErrMissingRegion))
 	if hook.LastEntry().Message != "AppConfiguration - Provide a valid apiKey." {
 		t.Errorf("Test failed: Incorrect error message")
 	}
@@ -43,89 +58,305 @@ func TestInit(t *testing.T) {
 
 	// test get feature when initialised properly
 	assert.Nil(t, ac.configurationHandlerInstance)
-	ac.Init("a", "b", "c")
+	err = ac.Init("a", "", "c")
+	assert.True(t, errors.Is(err, ErrMissingGUID))
+	assert.Nil(t, ac.configurationHandlerInstance)
+	err = ac.Init("a", "b", "c")
+	assert.Nil(t, err)
 	assert.NotNil(t, ac.configurationHandlerInstance)
 
 }
 
+func TestInitWithAuthenticator(t *testing.T) {
+	mockLogger()
+	ac := &AppConfiguration{configurationHandlerInstance: newConfigurationHandler()}
+	err := ac.InitWithAuthenticator("a", "b", nil)
+	assert.True(t, errors.Is(err, ErrMissingAuthenticator))
+	assert.Equal(t, "AppConfiguration - Provide a valid authenticator.", hook.LastEntry().Message)
+	assert.False(t, ac.isInitialized)
+	err = ac.InitWithAuthenticator("", "b", &core.NoAuthAuthenticator{})
+	assert.True(t, errors.Is(err, ErrMissingRegion))
+	err = ac.InitWithAuthenticator("a", "", &core.NoAuthAuthenticator{})
+	assert.True(t, errors.Is(err, ErrMissingGUID))
+
+	// the authenticator replaces the IAM authenticator when the context is set, and no api key is needed
+	authenticator := &core.BearerTokenAuthenticator{BearerToken: "token"}
+	ac, err = NewClient(ClientOptions{Region: "us-south", GUID: "guid", Authenticator: authenticator})
+	assert.Nil(t, err)
+	ch := ac.configurationHandlerInstance
+	ch.SetContext("c1", "dev", ContextOptions{LiveConfigUpdateEnabled: false, IAMURL: "https://iam.example.com"})
+	assert.Same(t, authenticator, ch.urlBuilder.GetAuthenticator())
+	assert.Equal(t, "https://iam.example.com", ch.urlBuilder.GetIAMURL())
+	assert.Equal(t, "Bearer token", ch.urlBuilder.GetToken())
+
+	// initializing with an api key again brings the IAM authenticator back
+	ac.Init("us-south", "guid", "apikey")
+	ch.SetContext("c1", "dev", ContextOptions{LiveConfigUpdateEnabled: false, IAMURL: "https://iam.example.com"})
+	iamAuthenticator, ok := ch.urlBuilder.GetAuthenticator().(*core.IamAuthenticator)
+	assert.True(t, ok)
+	assert.Equal(t, "apikey", iamAuthenticator.ApiKey)
+	assert.Equal(t, "https://iam.example.com", iamAuthenticator.URL)
+}
+
 func TestSetContext(t *testing.T) {
 	// test set context when is ac is not initialized properly
 	mockLogger()
 	ac := GetInstance()
 	ac.isInitialized = false
-	ac.SetContext("c1", "dev")
+	err := ac.SetContext("c1", "dev")
+	assert.True(t, errors.Is(err, ErrNotInitialized))
 	if hook.LastEntry().Message != "AppConfiguration - Invalid action. You can perform this action only after a successful initialization. Check the initialization section for errors." {
 		t.Errorf("Test failed: Incorrect error message")
 	}
 	reset(ac)
 	// when no collection id is provided
 	ac.isInitialized = true
-	ac.SetContext("", "dev")
+	err = ac.SetContext("", "dev")
+	assert.True(t, errors.Is(err, ErrMissingCollectionID))
 	if hook.LastEntry().Message != "AppConfiguration - Provide a valid collectionId." {
 		t.Errorf("Test failed: Incorrect error message")
 	}
 	reset(ac)
 	// when no environment id is provided
 	ac.isInitialized = true
-	ac.SetContext("c1", "")
+	err = ac.SetContext("c1", "")
+	assert.True(t, errors.Is(err, ErrMissingEnvironmentID))
 	if hook.LastEntry().Message != "AppConfiguration - Provide a valid environmentId." {
 		t.Errorf("Test failed: Incorrect error message")
 	}
 	reset(ac)
 
 	// when collection id and environment id is provided successfully. (in-memory cache)
-	ac.Init("a", "b", "c")
+	ac.Init("us-south", "b", "c")
 	ac.isInitialized = true
 	assert.Equal(t, false, ac.isInitializedConfig)
-	ac.SetContext("c1", "dev")
+	ac.SetContext("c1", "dev", ContextOptions{LiveConfigUpdateEnabled: true, RetryPolicy: &noRetryPolicy})
 	assert.Equal(t, true, ac.isInitializedConfig)
 	reset(ac)
 
 	// when collection id and environment id is provided successfully and the number of context options is more than 1
-	F := false
-	ac.Init("a", "b", "c")
+	ac.Init("us-south", "b", "c")
 	ac.isInitialized = true
-	ac.SetContext("c1", "dev", ContextOptions{
+	err = ac.SetContext("c1", "dev", ContextOptions{
 		BootstrapFile:           "saflights/flights.json",
-		LiveConfigUpdateEnabled: &F,
+		LiveConfigUpdateEnabled: false,
 	}, ContextOptions{
 		BootstrapFile:           "saflights/flights.json",
-		LiveConfigUpdateEnabled: &F,
+		LiveConfigUpdateEnabled: false,
 	})
+	assert.True(t, errors.Is(err, ErrIncorrectContextOptions))
 	if hook.LastEntry().Message != "AppConfiguration - Incorrect usage of context options. At most of one ContextOptions struct should be passed." {
 		t.Errorf("Test failed: Incorrect error message")
 	}
 	reset(ac)
 
 	// when collection id and environment id is provided successfully and the number of context options is 1. (Bootstrap file evaluation)
-	ac.Init("a", "b", "c")
+	ac.Init("us-south", "b", "c")
 	ac.isInitialized = true
 	assert.Equal(t, false, ac.isInitializedConfig)
-	ac.SetContext("c1", "dev", ContextOptions{
+	err = ac.SetContext("c1", "dev", ContextOptions{
 		BootstrapFile:           "saflights/flights.json",
-		LiveConfigUpdateEnabled: &F,
+		LiveConfigUpdateEnabled: false,
 	})
+	assert.True(t, errors.Is(err, ErrBootstrapFileUnreadable))
 	assert.Equal(t, true, ac.isInitializedConfig)
 	reset(ac)
 
 	// when collection id and environment id is provided successfully and the context options has no config file inspite of live update enabled set to false
-	ac.Init("a", "b", "c")
+	ac.Init("us-south", "b", "c")
 	ac.isInitialized = true
 	assert.Equal(t, false, ac.isInitializedConfig)
-	ac.SetContext("c1", "dev", ContextOptions{
+	err = ac.SetContext("c1", "dev", ContextOptions{
 		BootstrapFile:           "",
-		LiveConfigUpdateEnabled: &F,
+		LiveConfigUpdateEnabled: false,
 	})
-	if hook.LastEntry().Message != "AppConfiguration - Provide configuration_file value when live_config_update_enabled is false." {
+	assert.True(t, errors.Is(err, ErrBootstrapFileRequired))
+	if hook.LastEntry().Message != "AppConfiguration - Provide bootstrap_file value when live_config_update_enabled is false." {
 		t.Errorf("Test failed: Incorrect error message")
 	}
+	assert.Equal(t, false, ac.isInitializedConfig)
+	reset(ac)
+
+	// when the region is not a supported one
+	ac.Init("a", "b", "c")
+	err = ac.SetContext("c1", "dev", ContextOptions{LiveConfigUpdateEnabled: true, RetryPolicy: &noRetryPolicy})
+	assert.True(t, errors.Is(err, ErrUnknownRegion))
+	assert.Equal(t, false, ac.isInitializedConfig)
+
+	// when the endpoint is not a valid one
+	err = ac.SetContext("c1", "dev", ContextOptions{LiveConfigUpdateEnabled: true, RetryPolicy: &noRetryPolicy, Endpoint: "direct"})
+	assert.True(t, errors.Is(err, ErrInvalidEndpoint))
+
+	// when the base urls do not have the expected scheme
+	err = ac.SetContext("c1", "dev", ContextOptions{LiveConfigUpdateEnabled: true, RetryPolicy: &noRetryPolicy, BaseURL: "localhost:8080"})
+	assert.True(t, errors.Is(err, ErrInvalidBaseURL))
+	err = ac.SetContext("c1", "dev", ContextOptions{LiveConfigUpdateEnabled: true, RetryPolicy: &noRetryPolicy, BaseURL: "http://localhost:8080", WebSocketBaseURL: "http://localhost:8080"})
+	assert.True(t, errors.Is(err, ErrInvalidBaseURL))
 	reset(ac)
 }
+func TestBaseURL(t *testing.T) {
+	// a local stand-in of the service is reached over plain http and ws
+	connected := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if websocket.IsWebSocketUpgrade(req) {
+			upgrader := websocket.Upgrader{}
+			ws, err := upgrader.Upgrade(res, req, nil)
+			if err == nil {
+				close(connected)
+				defer ws.Close()
+				ws.ReadMessage()
+			}
+			return
+		}
+		res.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(res, `{"features":[{"name":"Cycle Rentals","feature_id":"cycle-rentals","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[],"segments":[]}`)
+	}))
+	defer server.Close()
+
+	ac, err := NewClient(ClientOptions{Region: "local", GUID: "guid", Authenticator: &core.NoAuthAuthenticator{}})
+	assert.Nil(t, err)
+	err = ac.SetContext("c1", "dev", ContextOptions{LiveConfigUpdateEnabled: true, RetryPolicy: &noRetryPolicy, BaseURL: server.URL})
+	assert.Nil(t, err)
+	feature, err := ac.GetFeature("cycle-rentals")
+	assert.Nil(t, err)
+	assert.Equal(t, "Cycle Rentals", feature.Name)
+	select {
+	case <-connected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Test failed: web socket not connected")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(t, ac.Close(ctx))
+}
+
+func TestNewClientError(t *testing.T) {
+	// a client which fails to initialize is not returned and leaves nothing running in the background
+	before := runtime.NumGoroutine()
+	for i := 0; i < 20; i++ {
+		ac, err := NewClient(ClientOptions{Region: "us-south", GUID: "guid"})
+		assert.True(t, errors.Is(err, ErrMissingAPIKey))
+		assert.Nil(t, ac)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+}
+
+func TestNewClient(t *testing.T) {
+	// clients created with NewClient do not share the configuration handler, url builder or metering instance
+	c1, err := NewClient(ClientOptions{Region: "us-south", GUID: "guid1", APIKey: "apikey1"})
+	assert.Nil(t, err)
+	c2, err := NewClient(ClientOptions{Region: "eu-gb", GUID: "guid2", APIKey: "apikey2"})
+	assert.Nil(t, err)
+	assert.Equal(t, true, c1.isInitialized)
+	assert.Equal(t, true, c2.isInitialized)
+	assert.NotSame(t, c1.configurationHandlerInstance, c2.configurationHandlerInstance)
+	assert.NotSame(t, GetConfigurationHandlerInstance(), c1.configurationHandlerInstance)
+	assert.NotSame(t, c1.configurationHandlerInstance.urlBuilder, c2.configurationHandlerInstance.urlBuilder)
+	assert.NotSame(t, c1.configurationHandlerInstance.metering, c2.configurationHandlerInstance.metering)
+
+	// each client serves the configurations of its own context
+	dir := t.TempDir()
+	data1 := `{"features":[{"name":"Cycle Rentals","feature_id":"cycle-rentals","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[{"rules":[{"segments":["ka761hap"]}],"value":false,"order":1}],"enabled":true}],"properties":[],"segments":[{"name":"ibm employees","segment_id":"ka761hap","rules":[{"values":["ibm.com"],"operator":"endsWith","attribute_name":"email"}]}]}`
+	data2 := `{"features":[{"name":"Cycle Rentals","feature_id":"cycle-rentals","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[{"rules":[{"segments":["ka761hap"]}],"value":false,"order":1}],"enabled":true}],"properties":[],"segments":[{"name":"ibm employees","segment_id":"ka761hap","rules":[{"values":["in.ibm.com"],"operator":"endsWith","attribute_name":"email"}]}]}`
+	ioutil.WriteFile(path.Join(dir, "c1.json"), []byte(data1), 0644)
+	ioutil.WriteFile(path.Join(dir, "c2.json"), []byte(data2), 0644)
+	err = c1.SetContext("c1", "dev", ContextOptions{
+		BootstrapFile:           path.Join(dir, "c1.json"),
+		LiveConfigUpdateEnabled: false,
+	})
+	assert.Nil(t, err)
+	err = c2.SetContext("c2", "prod", ContextOptions{
+		BootstrapFile:           path.Join(dir, "c2.json"),
+		LiveConfigUpdateEnabled: false,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "https://us-south.apprapp.cloud.ibm.com", c1.configurationHandlerInstance.urlBuilder.GetBaseServiceURL())
+	assert.Equal(t, "https://eu-gb.apprapp.cloud.ibm.com", c2.configurationHandlerInstance.urlBuilder.GetBaseServiceURL())
+
+	entityAttributes := map[string]interface{}{"email": "john@us.ibm.com"}
+	feature1, err := c1.GetFeature("cycle-rentals")
+	assert.Nil(t, err)
+	feature2, err := c2.GetFeature("cycle-rentals")
+	assert.Nil(t, err)
+	assert.Equal(t, false, feature1.GetCurrentValue("john", entityAttributes))
+	assert.Equal(t, true, feature2.GetCurrentValue("john", entityAttributes))
+}
+
+func TestWaitForReady(t *testing.T) {
+	// when the context is not set
+	ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", APIKey: "apikey"})
+	source, err := ac.WaitForReady(context.Background())
+	assert.True(t, errors.Is(err, ErrContextNotSet))
+	assert.Equal(t, ConfigurationSource(""), source)
+
+	// when the configurations are loaded from the bootstrap file
+	dir := t.TempDir()
+	data := `{"features":[],"properties":[],"segments":[]}`
+	ioutil.WriteFile(path.Join(dir, "bootstrap.json"), []byte(data), 0644)
+	ac.SetContext("c1", "dev", ContextOptions{
+		BootstrapFile:           path.Join(dir, "bootstrap.json"),
+		LiveConfigUpdateEnabled: false,
+	})
+	source, err = ac.WaitForReady(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, ConfigurationSourceBootstrapFile, source)
+	closeCtx, closeCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer closeCancel()
+	assert.Nil(t, ac.Close(closeCtx))
+
+	// when the configurations are loaded from the persistent cache, which takes precedence over the bootstrap file
+	persistentCache, _ := json.Marshal(utils.NewPersistentCache("guid", "c1", "dev", "", time.Now(), []byte(data)))
+	ioutil.WriteFile(path.Join(dir, "appconfiguration.json"), persistentCache, 0644)
+	ac, _ = NewClient(ClientOptions{Region: "us-south", GUID: "guid", APIKey: "apikey"})
+	ac.SetContext("c1", "dev", ContextOptions{
+		PersistentCacheDirectory: dir,
+		BootstrapFile:            path.Join(dir, "bootstrap.json"),
+		LiveConfigUpdateEnabled:  false,
+	})
+	source, err = ac.WaitForReady(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, ConfigurationSourcePersistentCache, source)
+	assert.Nil(t, ac.Close(closeCtx))
+
+	// when the configurations are loaded from the server
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-type", "application/json")
+		fmt.Fprint(res, data)
+	}))
+	defer server.Close()
+	ac, _ = NewClient(ClientOptions{Region: "us-south", GUID: "guid", Authenticator: &core.NoAuthAuthenticator{}})
+	ac.SetContext("c1", "dev", ContextOptions{LiveConfigUpdateEnabled: true, BaseURL: server.URL, RetryPolicy: &noRetryPolicy, RefreshMode: RefreshModeManual})
+	source, err = ac.WaitForReady(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, ConfigurationSourceServer, source)
+	assert.Nil(t, ac.Close(closeCtx))
+
+	// when the configurations are not loaded before the deadline
+	ac, _ = NewClient(ClientOptions{Region: "us-south", GUID: "guid", APIKey: "apikey"})
+	ac.isInitializedConfig = true
+	ac.configurationHandlerInstance.SetContext("c1", "dev", ContextOptions{LiveConfigUpdateEnabled: false})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = ac.WaitForReady(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// when the configurations are loaded while waiting
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		ac.configurationHandlerInstance.saveInCache([]byte(data), ConfigurationSourceServer)
+	}()
+	source, err = ac.WaitForReady(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, ConfigurationSourceServer, source)
+}
+
 func TestGetFeature(t *testing.T) {
 	// test get feature when not initialised properly
 	ac := GetInstance()
 	_, err := ac.GetFeature("FID1")
 	assert.Error(t, err, "Expected GetFeature to return error")
+	assert.True(t, errors.Is(err, ErrContextNotSet))
 	reset(ac)
 
 	// test get feature when config has been initialized properly and feature exists in the cache
@@ -143,6 +374,7 @@ func TestGetFeatures(t *testing.T) {
 	ac := GetInstance()
 	_, err := ac.GetFeatures()
 	assert.Error(t, err, "Expected GetFeatures to return error")
+	assert.True(t, errors.Is(err, ErrContextNotSet))
 	reset(ac)
 
 	// test get features when config has been initialized properly and feature exists in the cache
@@ -159,6 +391,7 @@ func TestGetProperty(t *testing.T) {
 	ac := GetInstance()
 	_, err := ac.GetProperty("PID1")
 	assert.Error(t, err, "Expected GetFeature to return error")
+	assert.True(t, errors.Is(err, ErrContextNotSet))
 	reset(ac)
 
 	// test get feature when config has been initialized properly and feature exists in the cache
@@ -176,6 +409,7 @@ func TestGetProperties(t *testing.T) {
 	ac := GetInstance()
 	_, err := ac.GetProperties()
 	assert.Error(t, err, "Expected GetProperties to return error")
+	assert.True(t, errors.Is(err, ErrContextNotSet))
 	reset(ac)
 	// test get properties when config has been initialized properly and property exists in the cache
 	mockInit(ac)
@@ -187,6 +421,241 @@ func TestGetProperties(t *testing.T) {
 	reset(ac)
 }
 
+func TestGetFeatureTypedValues(t *testing.T) {
+	ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", APIKey: "apikey"})
+	ac.isInitializedConfig = true
+	data := `{"features":[{"name":"Discount","feature_id":"discount","type":"NUMERIC","enabled_value":25,"disabled_value":0,"segment_rules":[],"enabled":true}],"properties":[{"name":"Theme","property_id":"theme","type":"STRING","format":"TEXT","value":"dark","segment_rules":[]}],"segments":[]}`
+	ac.configurationHandlerInstance.saveInCache([]byte(data), ConfigurationSourceServer)
+
+	discount, err := ac.GetFeatureIntValue("discount", "entityID", nil, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 25, discount)
+	enabled, err := ac.GetFeatureBoolValue("discount", "entityID", nil, false)
+	assert.True(t, errors.Is(err, ErrWrongDataType))
+	assert.Equal(t, false, enabled)
+	theme, err := ac.GetPropertyStringValue("theme", "entityID", nil, "light")
+	assert.Nil(t, err)
+	assert.Equal(t, "dark", theme)
+
+	// when the id does not exist
+	theme, err = ac.GetPropertyStringValue("colour", "entityID", nil, "light")
+	assert.Error(t, err)
+	assert.Equal(t, "light", theme)
+}
+
+func TestFlagAndProp(t *testing.T) {
+	ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", APIKey: "apikey"})
+	ac.isInitializedConfig = true
+	data := `{"features":[{"name":"Dark Mode","feature_id":"dark-mode","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true},{"name":"Browsers","feature_id":"browsers","type":"STRING","format":"JSON","enabled_value":{"name":"Firefox","version":90},"disabled_value":{},"segment_rules":[],"enabled":true}],"properties":[{"name":"Replicas","property_id":"replicas","type":"NUMERIC","value":3,"segment_rules":[]},{"name":"Limits","property_id":"limits","type":"STRING","format":"YAML","value":"cpu: 2\nmemory: 4Gi","segment_rules":[]},{"name":"Region","property_id":"region","type":"STRING","format":"TEXT","value":"us-south","segment_rules":[]}],"segments":[]}`
+
+	darkMode := BoolFlag(ac, "dark-mode", false)
+	replicas := IntProp(ac, "replicas", 1)
+	// the handles are created before the configurations are loaded and resolve them on every evaluation
+	value, err := darkMode.Evaluate("entityID", nil)
+	assert.Error(t, err)
+	assert.Equal(t, false, value)
+	ac.configurationHandlerInstance.saveInCache([]byte(data), ConfigurationSourceServer)
+
+	value, err = darkMode.Evaluate("entityID", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, true, value)
+	count, err := replicas.Evaluate("entityID", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+
+	type browser struct {
+		Name    string `json:"name"`
+		Version int    `json:"version"`
+	}
+	b, err := NewFlag(ac, "browsers", browser{Name: "default"}).Evaluate("entityID", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, browser{Name: "Firefox", Version: 90}, b)
+	limits, err := NewProp(ac, "limits", map[string]string{}).Evaluate("entityID", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "4Gi", limits["memory"])
+
+	// when the type does not match
+	s, err := StringFlag(ac, "dark-mode", "off").Evaluate("entityID", nil)
+	assert.True(t, errors.Is(err, ErrWrongDataType))
+	assert.Equal(t, "off", s)
+	_, err = NewProp(ac, "replicas", []string{}).Evaluate("entityID", nil)
+	assert.True(t, errors.Is(err, ErrWrongDataType))
+
+	// when the format does not match
+	region, err := NewProp(ac, "region", map[string]string{"name": "default"}).Evaluate("entityID", nil)
+	assert.True(t, errors.Is(err, ErrWrongDataFormat))
+	assert.False(t, errors.Is(err, ErrWrongDataType))
+	assert.Equal(t, "default", region["name"])
+}
+
+func TestEvaluationContext(t *testing.T) {
+	ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", APIKey: "apikey"})
+	ac.isInitializedConfig = true
+	data := `{"features":[{"name":"Beta","feature_id":"beta","type":"BOOLEAN","enabled_value":false,"disabled_value":false,"segment_rules":[{"rules":[{"segments":["testers"]}],"value":true,"order":1}],"enabled":true},{"name":"Discount","feature_id":"discount","type":"NUMERIC","enabled_value":0,"disabled_value":0,"segment_rules":[{"rules":[{"segments":["early"]}],"value":10,"order":1}],"enabled":true}],"properties":[{"name":"Theme","property_id":"theme","type":"STRING","value":"light","segment_rules":[{"rules":[{"segments":["testers"]}],"value":"dark","order":1}]}],"segments":[{"name":"Testers","segment_id":"testers","rules":[{"values":["qa"],"operator":"is","attribute_name":"groups"}]},{"name":"Early","segment_id":"early","rules":[{"values":["1609459200000"],"operator":"lesserThan","attribute_name":"signup"}]}]}`
+	ac.configurationHandlerInstance.saveInCache([]byte(data), ConfigurationSourceServer)
+
+	base := NewEvaluationContext("entityID").WithString("email", "alice@example.com").WithBool("premium", true)
+	ec := base.WithStringList("groups", []string{"dev", "qa"}).WithTime("signup", time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)).WithNumber("age", 30)
+	assert.Equal(t, "entityID", ec.GetEntityID())
+	assert.Equal(t, float64(30), ec.GetAttributes()["age"])
+	// the base context is not modified by deriving another one from it
+	assert.Len(t, base.GetAttributes(), 2)
+
+	ctx := ContextWithEvaluationContext(context.Background(), ec)
+	beta, err := ac.BoolFlag(ctx, "beta", false)
+	assert.Nil(t, err)
+	assert.Equal(t, true, beta)
+	discount, err := ac.IntFlag(ctx, "discount", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 10, discount)
+	theme, err := ac.StringProp(ctx, "theme", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "dark", theme)
+	theme, err = StringProp(ac, "theme", "").EvaluateContext(ContextWithEvaluationContext(context.Background(), base))
+	assert.Nil(t, err)
+	assert.Equal(t, "light", theme)
+
+	// without an evaluation context the default value is returned
+	beta, err = ac.BoolFlag(context.Background(), "beta", true)
+	assert.True(t, errors.Is(err, ErrMissingEvaluationContext))
+	assert.Equal(t, true, beta)
+	var into map[string]interface{}
+	assert.True(t, errors.Is(ac.JSONProp(context.Background(), "theme", &into), ErrMissingEvaluationContext))
+}
+
+func TestSnapshot(t *testing.T) {
+	ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", APIKey: "apikey"})
+	_, err := ac.Snapshot()
+	assert.True(t, errors.Is(err, ErrContextNotSet))
+	_, err = ac.GetFeature("f")
+	assert.True(t, errors.Is(err, ErrContextNotSet))
+	_, err = ac.GetFeatures()
+	assert.True(t, errors.Is(err, ErrContextNotSet))
+	_, err = ac.GetProperty("p")
+	assert.True(t, errors.Is(err, ErrContextNotSet))
+	_, err = ac.GetProperties()
+	assert.True(t, errors.Is(err, ErrContextNotSet))
+	ac.isInitializedConfig = true
+	_, err = ac.Snapshot()
+	assert.True(t, errors.Is(err, ErrConfigurationsNotLoaded))
+	_, err = (&Snapshot{}).GetFeature("f")
+	assert.True(t, errors.Is(err, ErrConfigurationsNotLoaded))
+	_, err = (&Snapshot{}).GetFeatures()
+	assert.True(t, errors.Is(err, ErrConfigurationsNotLoaded))
+	_, err = (&Snapshot{}).GetProperty("p")
+	assert.True(t, errors.Is(err, ErrConfigurationsNotLoaded))
+	_, err = (&Snapshot{}).GetProperties()
+	assert.True(t, errors.Is(err, ErrConfigurationsNotLoaded))
+
+	// version 1 puts ibm.com users in the segment, version 2 example.com users
+	config := func(domain string, value int) []byte {
+		return []byte(fmt.Sprintf(`{"features":[{"name":"F","feature_id":"f","type":"NUMERIC","enabled_value":0,"disabled_value":0,"segment_rules":[{"rules":[{"segments":["s"]}],"value":%d,"order":1}],"enabled":true}],"properties":[{"name":"P","property_id":"p","type":"NUMERIC","value":0,"segment_rules":[{"rules":[{"segments":["s"]}],"value":%d,"order":1}]}],"segments":[{"name":"S","segment_id":"s","rules":[{"values":["%s"],"operator":"endsWith","attribute_name":"email"}]}]}`, value, value, domain))
+	}
+	ch := ac.configurationHandlerInstance
+	ch.saveInCache(config("ibm.com", 1), ConfigurationSourceServer)
+	snapshot, err := ac.Snapshot()
+	assert.Nil(t, err)
+	feature, _ := ac.GetFeature("f")
+	ch.saveInCache(config("example.com", 2), ConfigurationSourceServer)
+
+	// the snapshot and the feature read before the update keep evaluating against version 1
+	attributes := map[string]interface{}{"email": "alice@ibm.com"}
+	assert.Equal(t, float64(1), feature.GetCurrentValue("entityID", attributes))
+	snapshotFeature, err := snapshot.GetFeature("f")
+	assert.Nil(t, err)
+	assert.Equal(t, float64(1), snapshotFeature.GetCurrentValue("entityID", attributes))
+	snapshotProperty, err := snapshot.GetProperty("p")
+	assert.Nil(t, err)
+	assert.Equal(t, float64(1), snapshotProperty.GetCurrentValue("entityID", attributes))
+	features, _ := snapshot.GetFeatures()
+	assert.Len(t, features, 1)
+	properties, _ := snapshot.GetProperties()
+	assert.Len(t, properties, 1)
+	_, err = snapshot.GetFeature("missing")
+	assert.Error(t, err)
+
+	// while the client evaluates against version 2
+	value, err := ac.GetFeatureIntValue("f", "entityID", attributes, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, value)
+	value, err = ac.GetFeatureIntValue("f", "entityID", map[string]interface{}{"email": "bob@example.com"}, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, value)
+}
+
+func TestConcurrentEvaluationsDuringRefreshes(t *testing.T) {
+	// run with -race: evaluations read the cache while refreshes replace it
+	ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", APIKey: "apikey"})
+	ac.isInitializedConfig = true
+	config := func(value string) []byte {
+		return []byte(`{"features":[{"name":"F","feature_id":"f","type":"STRING","format":"TEXT","enabled_value":"` + value + `","disabled_value":"off","segment_rules":[{"rules":[{"segments":["s"]}],"value":"$default","order":1}],"enabled":true}],"properties":[{"name":"P","property_id":"p","type":"STRING","format":"TEXT","value":"` + value + `","segment_rules":[]}],"segments":[{"name":"S","segment_id":"s","rules":[{"values":["ibm.com"],"operator":"endsWith","attribute_name":"email"}]}]}`)
+	}
+	ch := ac.configurationHandlerInstance
+	assert.Nil(t, ch.updateCacheAndListener(config("v0"), ConfigurationSourceServer))
+	unsubscribe := ac.AddConfigurationUpdateListener(func(ChangeSet) {})
+	defer unsubscribe()
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; ; j++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				ch.updateCacheAndListener(config(fmt.Sprintf("v%d", (i+j)%2)), ConfigurationSourceWebSocket)
+			}
+		}(i)
+	}
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			attributes := map[string]interface{}{"email": "alice@ibm.com"}
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				value, err := ac.GetFeatureStringValue("f", "entityID", attributes, "")
+				if err != nil || (value != "v0" && value != "v1") {
+					errs <- fmt.Errorf("unexpected value %q: %v", value, err)
+					return
+				}
+				features, _ := ac.GetFeatures()
+				// the map is a copy, modifying it does not affect the cache
+				delete(features, "f")
+				properties, _ := ac.GetProperties()
+				delete(properties, "p")
+				property, err := ac.GetProperty("p")
+				if err != nil {
+					errs <- err
+					return
+				}
+				property.GetCurrentValue("entityID", attributes)
+			}
+		}()
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	_, err := ac.GetFeature("f")
+	assert.Nil(t, err)
+}
+
+// noRetryPolicy : Retry policy of the tests that do not need the failed requests to be retried
+var noRetryPolicy = RetryPolicy{MaxAttempts: 1}
+
 func reset(ac *AppConfiguration) {
 	ac.isInitializedConfig = false
 	ac.configurationHandlerInstance = nil
@@ -206,12 +675,12 @@ func mockSetCache(ac *AppConfiguration) {
 	var cacheInstance *models.Cache
 	cacheInstance = new(models.Cache)
 	cacheInstance.FeatureMap = featureMap
-	ac.configurationHandlerInstance.cache = cacheInstance
+	ac.configurationHandlerInstance.storeCache(cacheInstance)
 
 	var testProperty models.Property
 	testProperty.Name = "nodeReplica"
 	testProperty.PropertyID = "PID1"
 	propertyMap["PID1"] = testProperty
 	cacheInstance.PropertyMap = propertyMap
-	ac.configurationHandlerInstance.cache = cacheInstance
+	ac.configurationHandlerInstance.storeCache(cacheInstance)
 }
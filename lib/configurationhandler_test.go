@@ -17,11 +17,18 @@
 package lib
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -50,11 +57,10 @@ func TestInitConfigurationHandlerInstance(t *testing.T) {
 }
 func TestConfigHandlerSetContext(t *testing.T) {
 	// test set context when initialised properly
-	F := false
 	ch := GetConfigurationHandlerInstance()
 	ch.SetContext("c1", "dev", ContextOptions{
 		BootstrapFile:           "flights.json",
-		LiveConfigUpdateEnabled: &F,
+		LiveConfigUpdateEnabled: false,
 	})
 	assert.Equal(t, "c1", ch.collectionID)
 	assert.Equal(t, "dev", ch.environmentID)
@@ -66,17 +72,17 @@ func TestSaveCache(t *testing.T) {
 	// test save feature when empty data is passed.
 	ch := GetConfigurationHandlerInstance()
 	data := `{"Features":null,"Properties":null,"Collection":{"name":"","collection_id":""},"Segments":null}`
-	ch.saveInCache([]byte(data))
-	assert.Equal(t, 0, len(ch.cache.FeatureMap))
-	assert.Equal(t, 0, len(ch.cache.PropertyMap))
-	assert.Equal(t, 0, len(ch.cache.SegmentMap))
+	ch.saveInCache([]byte(data), ConfigurationSourceServer)
+	assert.Equal(t, 0, len(ch.loadCache().FeatureMap))
+	assert.Equal(t, 0, len(ch.loadCache().PropertyMap))
+	assert.Equal(t, 0, len(ch.loadCache().SegmentMap))
 
 	// test save feature when non-empty data is passed.
 	data = `{"features":[{"name":"Cycle Rentals8","feature_id":"cycle-rentals8","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[{"name":"p1","property_id":"p1","tags":"","type":"BOOLEAN","value":false,"segment_rules":[],"created_time":"2021-05-26T06:23:18Z","updated_time":"2021-06-08T03:38:38Z","evaluation_time":"2021-06-03T10:08:46Z"}],"segments":[{"name":"beta-users","segment_id":"knliu818","rules":[{"values":["ibm.com"],"operator":"contains","attribute_name":"email"}]},{"name":"ibm employees","segment_id":"ka761hap","rules":[{"values":["ibm.com","in.ibm.com"],"operator":"endsWith","attribute_name":"email"}]}]}`
-	ch.saveInCache([]byte(data))
-	assert.Equal(t, 1, len(ch.cache.FeatureMap))
-	assert.Equal(t, 1, len(ch.cache.PropertyMap))
-	assert.Equal(t, 2, len(ch.cache.SegmentMap))
+	ch.saveInCache([]byte(data), ConfigurationSourceServer)
+	assert.Equal(t, 1, len(ch.loadCache().FeatureMap))
+	assert.Equal(t, 1, len(ch.loadCache().PropertyMap))
+	assert.Equal(t, 2, len(ch.loadCache().SegmentMap))
 }
 
 func TestFetchApi(t *testing.T) {
@@ -95,18 +101,20 @@ func TestFetchApi(t *testing.T) {
 	ch.urlBuilder.SetAuthenticator(&core.NoAuthAuthenticator{})
 	ch.liveConfigUpdateEnabled = true
 	ch.fetchFromAPI()
-	assert.Equal(t, 1, len(ch.cache.FeatureMap))
-	assert.Equal(t, 1, len(ch.cache.PropertyMap))
-	assert.Equal(t, 2, len(ch.cache.SegmentMap))
-	assert.Equal(t, "Cycle Rentals", ch.cache.FeatureMap["cycle-rentals"].Name)
-	assert.Equal(t, "Show Ad", ch.cache.PropertyMap["show-ad"].Name)
+	assert.Equal(t, 1, len(ch.loadCache().FeatureMap))
+	assert.Equal(t, 1, len(ch.loadCache().PropertyMap))
+	assert.Equal(t, 2, len(ch.loadCache().SegmentMap))
+	assert.Equal(t, "Cycle Rentals", ch.loadCache().FeatureMap["cycle-rentals"].Name)
+	assert.Equal(t, "Show Ad", ch.loadCache().PropertyMap["show-ad"].Name)
 	ts.Close()
 	resetConfigurationHandler(ch)
 
 	// test fetch api when backend returns 500 response
 	// create a temp server which will act as our backend for the test
+	requests := 0
 	ts = httptest.NewServer(
 		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
 			w.Header().Set("Content-type", "application/json")
 			w.WriteHeader(500)
 		}))
@@ -115,20 +123,43 @@ func TestFetchApi(t *testing.T) {
 	ch.urlBuilder.Init("collectionID", "environmentID", "region", "guid", "apikey", ts.URL)
 	ch.urlBuilder.SetAuthenticator(&core.NoAuthAuthenticator{})
 	ch.liveConfigUpdateEnabled = true
-	ch.fetchFromAPI()
-	assert.Equal(t, 0, len(ch.cache.FeatureMap))
-	assert.Equal(t, 0, len(ch.cache.PropertyMap))
-	assert.Equal(t, 0, len(ch.cache.SegmentMap))
+	clock := &fakeClock{blockFrom: time.Minute}
+	ch.retryPolicy = &RetryPolicy{InitialDelay: time.Second, Multiplier: 2, MaxDelay: time.Minute, MaxAttempts: 3}
+	ch.clock = clock
+	err := ch.fetchFromAPI()
+	assert.True(t, errors.Is(err, ErrConfigFetchFailed))
+	assert.Equal(t, 3, requests)
+	// the next retry cycle is scheduled on the clock after the max delay
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, time.Minute}, clock.getDelays())
+	ch.stopRetry()
+
+	// the delay before the next retry cycle is randomised by the jitter of the policy
+	clock = &fakeClock{blockFrom: 10 * time.Second}
+	ch.retryPolicy = &RetryPolicy{InitialDelay: time.Second, Multiplier: 2, MaxDelay: time.Minute, Jitter: 0.5, MaxAttempts: 3}
+	ch.clock = clock
+	err = ch.fetchFromAPI()
+	assert.True(t, errors.Is(err, ErrConfigFetchFailed))
+	delays := clock.getDelays()
+	assert.Equal(t, 3, len(delays))
+	assert.True(t, delays[2] >= 30*time.Second && delays[2] <= 90*time.Second)
+	ch.stopRetry()
+	ch.stopRetry = nil
+	ch.retryPolicy = nil
+	ch.clock = nil
+	assert.Equal(t, 0, len(ch.loadCache().FeatureMap))
+	assert.Equal(t, 0, len(ch.loadCache().PropertyMap))
+	assert.Equal(t, 0, len(ch.loadCache().SegmentMap))
 	ts.Close()
 	resetConfigurationHandler(ch)
 
 	// test fetch api when configuration handler instance is not initialized
 	ch = GetConfigurationHandlerInstance()
 	ch.isInitialized = false
-	ch.fetchFromAPI()
-	assert.Equal(t, 0, len(ch.cache.FeatureMap))
-	assert.Equal(t, 0, len(ch.cache.PropertyMap))
-	assert.Equal(t, 0, len(ch.cache.SegmentMap))
+	err = ch.fetchFromAPI()
+	assert.True(t, errors.Is(err, ErrNotInitialized))
+	assert.Equal(t, 0, len(ch.loadCache().FeatureMap))
+	assert.Equal(t, 0, len(ch.loadCache().PropertyMap))
+	assert.Equal(t, 0, len(ch.loadCache().SegmentMap))
 	resetConfigurationHandler(ch)
 }
 
@@ -138,12 +169,12 @@ func TestUpdateCacheAndListener(t *testing.T) {
 	data := `{ "features": [ { "name": "Cycle Rentals", "feature_id": "cycle-rentals", "type": "BOOLEAN", "enabled_value": true, "disabled_value": false, "segment_rules": [], "enabled": true } ], "properties": [ { "name": "Show Ad", "property_id": "show-ad", "tags": "", "type": "BOOLEAN", "value": false, "segment_rules": [], "created_time": "2021-05-26T06:23:18Z", "updated_time": "2021-06-08T03:38:38Z", "evaluation_time": "2021-06-03T10:08:46Z" } ], "segments": [ { "name": "beta-users", "segment_id": "knliu818", "rules": [ { "values": [ "ibm.com" ], "operator": "contains", "attribute_name": "email" } ] }, { "name": "ibm employees", "segment_id": "ka761hap", "rules": [ { "values": [ "ibm.com", "in.ibm.com" ], "operator": "endsWith", "attribute_name": "email" } ] } ] }`
 	ch := GetConfigurationHandlerInstance()
 	ch.Init("us-south", "abc", "abc")
-	ch.updateCacheAndListener([]byte(data))
-	assert.Equal(t, 1, len(ch.cache.FeatureMap))
-	assert.Equal(t, 1, len(ch.cache.PropertyMap))
-	assert.Equal(t, 2, len(ch.cache.SegmentMap))
-	assert.Equal(t, "Cycle Rentals", ch.cache.FeatureMap["cycle-rentals"].Name)
-	assert.Equal(t, "Show Ad", ch.cache.PropertyMap["show-ad"].Name)
+	ch.updateCacheAndListener([]byte(data), ConfigurationSourceServer)
+	assert.Equal(t, 1, len(ch.loadCache().FeatureMap))
+	assert.Equal(t, 1, len(ch.loadCache().PropertyMap))
+	assert.Equal(t, 2, len(ch.loadCache().SegmentMap))
+	assert.Equal(t, "Cycle Rentals", ch.loadCache().FeatureMap["cycle-rentals"].Name)
+	assert.Equal(t, "Show Ad", ch.loadCache().PropertyMap["show-ad"].Name)
 	resetConfigurationHandler(ch)
 
 	// valid data and listener method provided
@@ -153,27 +184,28 @@ func TestUpdateCacheAndListener(t *testing.T) {
 	ch.configurationUpdateListener = func() {
 		msg = "Latest evaluation done."
 	}
-	ch.updateCacheAndListener([]byte(data))
+	ch.updateCacheAndListener([]byte(data), ConfigurationSourceServer)
 	assert.Equal(t, "Latest evaluation done.", msg)
 
-	assert.Equal(t, 1, len(ch.cache.FeatureMap))
-	assert.Equal(t, 1, len(ch.cache.PropertyMap))
-	assert.Equal(t, 2, len(ch.cache.SegmentMap))
-	assert.Equal(t, "Cycle Rentals", ch.cache.FeatureMap["cycle-rentals"].Name)
-	assert.Equal(t, "Show Ad", ch.cache.PropertyMap["show-ad"].Name)
+	assert.Equal(t, 1, len(ch.loadCache().FeatureMap))
+	assert.Equal(t, 1, len(ch.loadCache().PropertyMap))
+	assert.Equal(t, 2, len(ch.loadCache().SegmentMap))
+	assert.Equal(t, "Cycle Rentals", ch.loadCache().FeatureMap["cycle-rentals"].Name)
+	assert.Equal(t, "Show Ad", ch.loadCache().PropertyMap["show-ad"].Name)
 	resetConfigurationHandler(ch)
 
 	// invalid data
 	data = "<not a valid json>"
 	ch = GetConfigurationHandlerInstance()
 	ch.Init("us-south", "abc", "abc")
-	ch.updateCacheAndListener([]byte(data))
+	err := ch.updateCacheAndListener([]byte(data), ConfigurationSourceServer)
+	assert.True(t, errors.Is(err, ErrInvalidConfiguration))
 	if hook.LastEntry().Message != "AppConfiguration - Error while unmarshalling JSON invalid character '<' looking for beginning of value" {
 		t.Errorf("Test failed: Incorrect error message")
 	}
-	assert.Equal(t, 0, len(ch.cache.FeatureMap))
-	assert.Equal(t, 0, len(ch.cache.PropertyMap))
-	assert.Equal(t, 0, len(ch.cache.SegmentMap))
+	assert.Equal(t, 0, len(ch.loadCache().FeatureMap))
+	assert.Equal(t, 0, len(ch.loadCache().PropertyMap))
+	assert.Equal(t, 0, len(ch.loadCache().SegmentMap))
 	resetConfigurationHandler(ch)
 
 }
@@ -209,6 +241,119 @@ func TestRegisterConfigurationUpdateListener(t *testing.T) {
 
 }
 
+func TestWatchFeatureAndProperty(t *testing.T) {
+	ch := new(ConfigurationHandler)
+	ch.ctx, ch.cancel = context.WithCancel(context.Background())
+	config := func(enabled bool, value int) []byte {
+		return []byte(fmt.Sprintf(`{"features":[{"name":"F1","feature_id":"f1","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":%t},{"name":"F2","feature_id":"f2","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[{"name":"P1","property_id":"p1","type":"NUMERIC","value":%d,"segment_rules":[]}],"segments":[]}`, enabled, value))
+	}
+	ch.saveInCache(config(true, 1), ConfigurationSourceServer)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	features := ch.watchFeature(ctx, "f1")
+	properties := ch.watchProperty(context.Background(), "p1")
+	// the current values are received first
+	feature := <-features
+	assert.True(t, feature.IsEnabled())
+	property := <-properties
+	assert.Equal(t, float64(1), property.GetValue())
+
+	// changes of other entries are not received, slow receivers get the latest value only
+	ch.saveInCache(config(false, 1), ConfigurationSourceServer)
+	ch.saveInCache(config(true, 2), ConfigurationSourceServer)
+	ch.saveInCache(config(false, 3), ConfigurationSourceServer)
+	feature = <-features
+	assert.False(t, feature.IsEnabled())
+	property = <-properties
+	assert.Equal(t, float64(3), property.GetValue())
+	select {
+	case <-features:
+		t.Error("Test failed: no value expected")
+	default:
+	}
+
+	// entries using a segment changed by an update are received again, evaluating the new segment
+	segmented := func(domain string) []byte {
+		return []byte(`{"features":[{"name":"F1","feature_id":"f1","type":"BOOLEAN","enabled_value":false,"disabled_value":false,"segment_rules":[{"rules":[{"segments":["s1"]}],"value":true,"order":1}],"enabled":true}],"properties":[{"name":"P1","property_id":"p1","type":"NUMERIC","value":3,"segment_rules":[]}],"segments":[{"name":"S1","segment_id":"s1","rules":[{"values":["` + domain + `"],"operator":"endsWith","attribute_name":"email"}]}]}`)
+	}
+	ch.saveInCache(segmented("ibm.com"), ConfigurationSourceServer)
+	feature = <-features
+	assert.Equal(t, true, feature.GetCurrentValue("u1", map[string]interface{}{"email": "u1@ibm.com"}))
+	ch.saveInCache(segmented("example.com"), ConfigurationSourceServer)
+	select {
+	case feature = <-features:
+	default:
+		t.Fatal("Test failed: feature using the changed segment not received")
+	}
+	assert.Equal(t, false, feature.GetCurrentValue("u1", map[string]interface{}{"email": "u1@ibm.com"}))
+	select {
+	case <-properties:
+		t.Error("Test failed: no value expected for an entry not using the segment")
+	default:
+	}
+
+	// removed entries are received as empty values
+	ch.saveInCache([]byte(`{"features":[],"properties":[],"segments":[]}`), ConfigurationSourceServer)
+	feature = <-features
+	assert.Equal(t, "", feature.GetFeatureID())
+
+	// the channel is closed when the context is done
+	cancel()
+	_, ok := <-features
+	assert.False(t, ok)
+
+	// or when the handler is closed
+	property = <-properties
+	assert.Equal(t, "", property.GetPropertyID())
+	assert.Nil(t, ch.close(context.Background()))
+	_, ok = <-properties
+	assert.False(t, ok)
+	_, ok = <-ch.watchFeature(context.Background(), "f1")
+	assert.False(t, ok)
+}
+
+func TestAddConfigurationUpdateListener(t *testing.T) {
+	ch := new(ConfigurationHandler)
+	var first, second []ChangeSet
+	unsubscribeFirst := ch.addConfigurationUpdateListener(func(cs ChangeSet) {
+		first = append(first, cs)
+	})
+	ch.addConfigurationUpdateListener(func(cs ChangeSet) {
+		panic("a listener panicking does not stop the others")
+	})
+	ch.addConfigurationUpdateListener(func(cs ChangeSet) {
+		second = append(second, cs)
+	})
+
+	data := `{"features":[{"name":"F1","feature_id":"f1","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true},{"name":"F2","feature_id":"f2","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[{"name":"P1","property_id":"p1","type":"NUMERIC","value":1,"segment_rules":[]}],"segments":[{"name":"S1","segment_id":"s1","rules":[{"values":["ibm.com"],"operator":"endsWith","attribute_name":"email"}]}]}`
+	assert.Nil(t, ch.updateCacheAndListener([]byte(data), ConfigurationSourceBootstrapFile))
+	assert.Len(t, first, 1)
+	assert.Equal(t, ChangeSet{
+		Source:     ConfigurationSourceBootstrapFile,
+		Features:   Changes{Added: []string{"f1", "f2"}},
+		Properties: Changes{Added: []string{"p1"}},
+		Segments:   Changes{Added: []string{"s1"}},
+	}, first[0])
+	assert.Equal(t, first, second)
+
+	// f1 is modified, f2 removed, f3 added, the property and the segment are unchanged
+	data = `{"features":[{"name":"F1","feature_id":"f1","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":false},{"name":"F3","feature_id":"f3","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[{"name":"P1","property_id":"p1","type":"NUMERIC","value":1,"segment_rules":[]}],"segments":[{"name":"S1","segment_id":"s1","rules":[{"values":["ibm.com"],"operator":"endsWith","attribute_name":"email"}]}]}`
+	unsubscribeFirst()
+	unsubscribeFirst()
+	assert.Nil(t, ch.updateCacheAndListener([]byte(data), ConfigurationSourceWebSocket))
+	assert.Len(t, first, 1)
+	assert.Len(t, second, 2)
+	assert.Equal(t, ConfigurationSourceWebSocket, second[1].Source)
+	assert.Equal(t, Changes{Added: []string{"f3"}, Removed: []string{"f2"}, Modified: []string{"f1"}}, second[1].Features)
+	assert.True(t, second[1].Properties.IsEmpty())
+	assert.True(t, second[1].Segments.IsEmpty())
+	assert.False(t, second[1].IsEmpty())
+
+	// the same configurations again
+	assert.Nil(t, ch.updateCacheAndListener([]byte(data), ConfigurationSourceServer))
+	assert.True(t, second[2].IsEmpty())
+}
+
 func TestStartWebSocket(t *testing.T) {
 
 	// test start web socket when connection is done successfully
@@ -238,34 +383,1234 @@ func TestStartWebSocket(t *testing.T) {
 	ch.collectionID = "collectionID"
 	ch.liveConfigUpdateEnabled = true
 	ch.isInitialized = true
+	ctx, cancel := context.WithCancel(context.Background())
+	ch.mu.Lock()
+	previousCtx, previousCancel := ch.ctx, ch.cancel
+	ch.ctx, ch.cancel = ctx, cancel
+	ch.mu.Unlock()
+	updated := make(chan struct{}, 10)
+	unsubscribe := ch.addConfigurationUpdateListener(func(ChangeSet) {
+		updated <- struct{}{}
+	})
+	defer unsubscribe()
+	waitForUpdate := func() {
+		select {
+		case <-updated:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Test failed: configurations not fetched after connecting")
+		}
+	}
+	resetConfigurationHandler(ch)
 	ch.startWebSocket()
-	time.Sleep(2 * time.Second)
+	waitForUpdate()
 
-	assert.Equal(t, 1, len(ch.cache.FeatureMap))
-	assert.Equal(t, 1, len(ch.cache.PropertyMap))
-	assert.Equal(t, 2, len(ch.cache.SegmentMap))
-	assert.Equal(t, "Cycle Rentals", ch.cache.FeatureMap["cycle-rentals"].Name)
-	assert.Equal(t, "Show Ad", ch.cache.PropertyMap["show-ad"].Name)
+	assert.Equal(t, 1, len(ch.loadCache().FeatureMap))
+	assert.Equal(t, 1, len(ch.loadCache().PropertyMap))
+	assert.Equal(t, 2, len(ch.loadCache().SegmentMap))
+	assert.Equal(t, "Cycle Rentals", ch.loadCache().FeatureMap["cycle-rentals"].Name)
+	assert.Equal(t, "Show Ad", ch.loadCache().PropertyMap["show-ad"].Name)
 	resetConfigurationHandler(ch)
 
 	// test start web socket when web socket connection is already exists , and a new connection is created
 
 	ch.startWebSocket()
-	time.Sleep(2 * time.Second)
+	waitForUpdate()
+
+	assert.Equal(t, 1, len(ch.loadCache().FeatureMap))
+	assert.Equal(t, 1, len(ch.loadCache().PropertyMap))
+	assert.Equal(t, 2, len(ch.loadCache().SegmentMap))
+	assert.Equal(t, "Cycle Rentals", ch.loadCache().FeatureMap["cycle-rentals"].Name)
+	assert.Equal(t, "Show Ad", ch.loadCache().PropertyMap["show-ad"].Name)
+
+	// stop the web socket goroutines of the shared instance, so that they do not outlive the test
+	cancel()
+	ch.mu.Lock()
+	ch.socketConnection.Close()
+	ch.socketConnection = nil
+	ch.mu.Unlock()
+	ch.backgroundTasks.Wait()
+	ch.mu.Lock()
+	ch.ctx, ch.cancel = previousCtx, previousCancel
+	ch.fetchRequests = nil
+	ch.mu.Unlock()
+}
+
+func TestClose(t *testing.T) {
+	usageReceived := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if websocket.IsWebSocketUpgrade(req) {
+			wsEndpoint(res, req)
+			return
+		}
+		if strings.HasSuffix(req.URL.Path, "/usage") {
+			select {
+			case usageReceived <- struct{}{}:
+			default:
+			}
+			res.WriteHeader(202)
+			return
+		}
+		res.Header().Set("Content-type", "application/json")
+		res.WriteHeader(200)
+		fmt.Fprintf(res, "%s", `{"features":[{"name":"Cycle Rentals","feature_id":"cycle-rentals","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[],"segments":[]}`)
+	}))
+	defer server.Close()
+
+	ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", APIKey: "apikey"})
+	ch := ac.configurationHandlerInstance
+	ch.SetContext("collectionID", "environmentID", ContextOptions{LiveConfigUpdateEnabled: true})
+	ch.urlBuilder.Init("collectionID", "environmentID", "region", "guid", "apikey", server.URL)
+	ch.urlBuilder.SetWebSocketURL("ws" + strings.TrimPrefix(server.URL, "http"))
+	ch.urlBuilder.SetAuthenticator(&core.NoAuthAuthenticator{})
+	ac.isInitializedConfig = true
+	err := ch.loadData()
+	assert.Nil(t, err)
+	feature, _ := ac.GetFeature("cycle-rentals")
+	assert.Equal(t, true, feature.GetCurrentValue("entityID", nil))
+	ch.scheduleRetry(time.Hour)
+
+	// close flushes the metering data and waits for the web socket goroutines and the pending retry
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = ac.Close(ctx)
+	assert.Nil(t, err)
+	select {
+	case <-usageReceived:
+	default:
+		t.Errorf("Test failed: metering data not sent on close")
+	}
+
+	// nothing is started in the background once closed
+	started := false
+	ch.runInBackground(func() {
+		started = true
+	})
+	ch.backgroundTasks.Wait()
+	assert.Equal(t, false, started)
+
+	// closing again does nothing
+	err = ac.Close(ctx)
+	assert.Nil(t, err)
+}
+
+func TestCloseAbortsFetch(t *testing.T) {
+	requests := make(chan struct{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		requests <- struct{}{}
+		// the request is answered only once the client gives it up
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+
+	ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", Authenticator: &core.NoAuthAuthenticator{}})
+	ch := ac.configurationHandlerInstance
+	ch.SetContext("c1", "dev", ContextOptions{LiveConfigUpdateEnabled: true, BaseURL: server.URL, RetryPolicy: &utils.RetryPolicy{InitialDelay: time.Second, MaxAttempts: 3}, RefreshMode: RefreshModeManual})
+	// the retries wait until the handler is closed
+	ch.clock = &fakeClock{blockFrom: time.Second}
+	fetched := make(chan error, 1)
+	go func() {
+		fetched <- ch.fetchFromAPI()
+	}()
+	<-requests
+
+	// close aborts the request in flight, and no other attempt is made
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(t, ac.Close(ctx))
+	select {
+	case err := <-fetched:
+		assert.True(t, errors.Is(err, ErrConfigFetchFailed))
+	case <-time.After(5 * time.Second):
+		t.Fatal("Test failed: fetch not aborted by close")
+	}
+	assert.Equal(t, 0, len(requests))
+}
+
+func TestWebSocketReconnectBackoff(t *testing.T) {
+	var mu sync.Mutex
+	dials := 0
+	connected := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if websocket.IsWebSocketUpgrade(req) {
+			mu.Lock()
+			dials++
+			rejected := dials <= 5
+			mu.Unlock()
+			if rejected {
+				res.WriteHeader(503)
+				return
+			}
+			upgrader := websocket.Upgrader{}
+			ws, err := upgrader.Upgrade(res, req, nil)
+			if err == nil {
+				close(connected)
+				defer ws.Close()
+				ws.ReadMessage()
+			}
+			return
+		}
+		res.WriteHeader(202)
+	}))
+	defer server.Close()
+
+	ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", APIKey: "apikey"})
+	ch := ac.configurationHandlerInstance
+	clock := &fakeClock{}
+	ch.clock = clock
+	ch.SetContext("collectionID", "environmentID", ContextOptions{
+		LiveConfigUpdateEnabled: true,
+		RetryPolicy:             &RetryPolicy{InitialDelay: time.Second, Multiplier: 2, MaxDelay: 10 * time.Minute, MaxAttempts: 3},
+	})
+	ch.urlBuilder.Init("collectionID", "environmentID", "region", "guid", "apikey", server.URL)
+	ch.urlBuilder.SetWebSocketURL("ws" + strings.TrimPrefix(server.URL, "http"))
+	ch.urlBuilder.SetAuthenticator(&core.NoAuthAuthenticator{})
+	ch.runInBackground(ch.startWebSocket)
+
+	select {
+	case <-connected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Test failed: web socket not connected")
+	}
+	// the delays keep doubling once the attempts of the policy are used, instead of waiting for the max delay
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second}, clock.getDelays())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(t, ac.Close(ctx))
+}
+
+func TestTransportOptions(t *testing.T) {
+	var mu sync.Mutex
+	headers := map[string]string{}
+	connected := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if websocket.IsWebSocketUpgrade(req) {
+			mu.Lock()
+			headers["websocket"] = req.Header.Get("X-Test")
+			mu.Unlock()
+			upgrader := websocket.Upgrader{}
+			ws, err := upgrader.Upgrade(res, req, nil)
+			if err == nil {
+				close(connected)
+				defer ws.Close()
+				ws.ReadMessage()
+			}
+			return
+		}
+		mu.Lock()
+		headers["fetch"] = req.Header.Get("X-Test")
+		mu.Unlock()
+		res.Header().Set("Content-Type", "application/json")
+		res.WriteHeader(200)
+		fmt.Fprint(res, `{"features":[],"properties":[],"segments":[]}`)
+	}))
+	defer server.Close()
 
-	assert.Equal(t, 1, len(ch.cache.FeatureMap))
-	assert.Equal(t, 1, len(ch.cache.PropertyMap))
-	assert.Equal(t, 2, len(ch.cache.SegmentMap))
-	assert.Equal(t, "Cycle Rentals", ch.cache.FeatureMap["cycle-rentals"].Name)
-	assert.Equal(t, "Show Ad", ch.cache.PropertyMap["show-ad"].Name)
+	ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", APIKey: "apikey"})
+	ch := ac.configurationHandlerInstance
+	ch.SetContext("collectionID", "environmentID", ContextOptions{
+		LiveConfigUpdateEnabled: true,
+		RetryPolicy:             &noRetryPolicy,
+		HTTPClient:              &http.Client{},
+		RequestTimeout:          5 * time.Second,
+		Headers:                 http.Header{"X-Test": []string{"value"}},
+	})
+	ch.urlBuilder.Init("collectionID", "environmentID", "region", "guid", "apikey", server.URL)
+	ch.urlBuilder.SetWebSocketURL("ws" + strings.TrimPrefix(server.URL, "http"))
+	ch.urlBuilder.SetAuthenticator(&core.NoAuthAuthenticator{})
+
+	assert.Nil(t, ch.fetchFromAPI())
+	ch.runInBackground(ch.startWebSocket)
+	select {
+	case <-connected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Test failed: web socket not connected")
+	}
+	mu.Lock()
+	assert.Equal(t, map[string]string{"fetch": "value", "websocket": "value"}, headers)
+	mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(t, ac.Close(ctx))
+}
+
+func TestWatchBootstrapFile(t *testing.T) {
+	dir := t.TempDir()
+	bootstrapFile := path.Join(dir, "bootstrap.json")
+	config := func(name string) string {
+		return `{"features":[{"name":"` + name + `","feature_id":"cycle-rentals","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[],"segments":[]}`
+	}
+	modTime := time.Now()
+	writeFile := func(data string) {
+		assert.Nil(t, ioutil.WriteFile(bootstrapFile, []byte(data), 0644))
+		// a later modification time every write, whatever the resolution of the file system
+		modTime = modTime.Add(time.Second)
+		assert.Nil(t, os.Chtimes(bootstrapFile, modTime, modTime))
+	}
+	writeFile(config("Cycle Rentals"))
+
+	ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", APIKey: "apikey"})
+	changes := make(chan ChangeSet, 10)
+	ac.AddConfigurationUpdateListener(func(changeSet ChangeSet) {
+		changes <- changeSet
+	})
+	err := ac.SetContext("c1", "dev", ContextOptions{
+		BootstrapFile:              bootstrapFile,
+		LiveConfigUpdateEnabled:    false,
+		WatchBootstrapFile:         true,
+		BootstrapFileWatchInterval: 10 * time.Millisecond,
+	})
+	assert.Nil(t, err)
+	<-changes
+	nextChange := func() ChangeSet {
+		select {
+		case changeSet := <-changes:
+			return changeSet
+		case <-time.After(5 * time.Second):
+			t.Fatal("Test failed: bootstrap file not reloaded")
+			return ChangeSet{}
+		}
+	}
+
+	// a changed file is reloaded
+	writeFile(config("Cycle Rentals v2"))
+	changeSet := nextChange()
+	assert.Equal(t, ConfigurationSourceBootstrapFile, changeSet.Source)
+	assert.Equal(t, []string{"cycle-rentals"}, changeSet.Features.Modified)
+	feature, _ := ac.GetFeature("cycle-rentals")
+	assert.Equal(t, "Cycle Rentals v2", feature.Name)
+
+	// a malformed file keeps the previous configurations, and the next valid one is loaded
+	writeFile(`{"features":[`)
+	writeFile(config("Cycle Rentals v3"))
+	nextChange()
+	feature, _ = ac.GetFeature("cycle-rentals")
+	assert.Equal(t, "Cycle Rentals v3", feature.Name)
+	writeFile(`{"features":[`)
+	time.Sleep(50 * time.Millisecond)
+	feature, _ = ac.GetFeature("cycle-rentals")
+	assert.Equal(t, "Cycle Rentals v3", feature.Name)
+	assert.Equal(t, 0, len(changes))
+
+	// so does a valid json without the expected keys
+	for _, data := range []string{`{}`, `{"collection":{"features":[]}}`} {
+		writeFile(data)
+		time.Sleep(50 * time.Millisecond)
+		feature, _ = ac.GetFeature("cycle-rentals")
+		assert.Equal(t, "Cycle Rentals v3", feature.Name)
+		assert.Equal(t, 0, len(changes))
+	}
+
+	// a new context stops watching the bootstrap file of the previous one
+	otherBootstrapFile := path.Join(dir, "other.json")
+	assert.Nil(t, ioutil.WriteFile(otherBootstrapFile, []byte(config("Other")), 0644))
+	err = ac.SetContext("c1", "dev", ContextOptions{
+		BootstrapFile:           otherBootstrapFile,
+		LiveConfigUpdateEnabled: false,
+	})
+	assert.Nil(t, err)
+	nextChange()
+	writeFile(config("Cycle Rentals v4"))
+	time.Sleep(50 * time.Millisecond)
+	feature, _ = ac.GetFeature("cycle-rentals")
+	assert.Equal(t, "Other", feature.Name)
+	assert.Equal(t, 0, len(changes))
+
+	// the watcher stops when the client is closed
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(t, ac.Close(ctx))
+}
+
+func TestCorruptPersistentCache(t *testing.T) {
+	mockLogger()
+	dir := t.TempDir()
+	bootstrapFile := path.Join(dir, "bootstrap.json")
+	data := `{"features":[{"name":"Cycle Rentals","feature_id":"cycle-rentals","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[],"segments":[]}`
+	assert.Nil(t, ioutil.WriteFile(bootstrapFile, []byte(data), 0644))
+	// a persistent cache truncated by a crash
+	assert.Nil(t, ioutil.WriteFile(path.Join(dir, "appconfiguration.json"), []byte(data[:40]), 0644))
+
+	ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", APIKey: "apikey"})
+	err := ac.SetContext("c1", "dev", ContextOptions{
+		PersistentCacheDirectory: dir,
+		BootstrapFile:            bootstrapFile,
+		LiveConfigUpdateEnabled:  false,
+	})
+	assert.Nil(t, err)
+	feature, err := ac.GetFeature("cycle-rentals")
+	assert.Nil(t, err)
+	assert.Equal(t, "Cycle Rentals", feature.Name)
+	assert.Equal(t, ConfigurationSourceBootstrapFile, ac.configurationHandlerInstance.cacheSource)
+
+	// the bootstrap file replaces the corrupt persistent cache
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(t, ac.Close(ctx))
+	persistentData, _ := ioutil.ReadFile(path.Join(dir, "appconfiguration.json"))
+	assert.True(t, json.Valid(persistentData))
+}
+
+func TestPersistentCacheScope(t *testing.T) {
+	mockLogger()
+	dir := t.TempDir()
+	config := func(name string) string {
+		return `{"features":[{"name":"` + name + `","feature_id":"cycle-rentals","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[],"segments":[]}`
+	}
+	bootstrapFile := path.Join(dir, "bootstrap.json")
+	assert.Nil(t, ioutil.WriteFile(bootstrapFile, []byte(config("Bootstrap")), 0644))
+	writeCache := func(environmentID string, fetchedAt time.Time) {
+		data, _ := json.Marshal(utils.NewPersistentCache("guid", "c1", environmentID, `"etag"`, fetchedAt, []byte(config("Cached"))))
+		assert.Nil(t, ioutil.WriteFile(path.Join(dir, "appconfiguration.json"), data, 0644))
+	}
+	load := func(environmentID string, maxAge time.Duration) string {
+		ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", APIKey: "apikey"})
+		ac.SetContext("c1", environmentID, ContextOptions{
+			PersistentCacheDirectory: dir,
+			PersistentCacheMaxAge:    maxAge,
+			BootstrapFile:            bootstrapFile,
+			LiveConfigUpdateEnabled:  false,
+		})
+		feature, _ := ac.GetFeature("cycle-rentals")
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		assert.Nil(t, ac.Close(ctx))
+		return feature.Name
+	}
 
+	// the cache of the context is loaded
+	writeCache("dev", time.Now())
+	assert.Equal(t, "Cached", load("dev", 0))
+
+	// the cache of another environment is ignored, and replaced by the bootstrap file of this one
+	writeCache("dev", time.Now())
+	assert.Equal(t, "Bootstrap", load("prod", 0))
+	persistentCache, err := utils.ParsePersistentCache(utils.ReadFiles(path.Join(dir, "appconfiguration.json")))
+	assert.Nil(t, err)
+	assert.Equal(t, "prod", persistentCache.EnvironmentID)
+
+	// a cache older than the max age is ignored
+	writeCache("dev", time.Now().Add(-2*time.Hour))
+	assert.Equal(t, "Cached", load("dev", 0))
+	writeCache("dev", time.Now().Add(-2*time.Hour))
+	assert.Equal(t, "Bootstrap", load("dev", time.Hour))
+}
+
+func TestConditionalFetch(t *testing.T) {
+	mockLogger()
+	var mu sync.Mutex
+	etag := `"v1"`
+	name := "Cycle Rentals"
+	var ifNoneMatch []string
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		ifNoneMatch = append(ifNoneMatch, req.Header.Get("If-None-Match"))
+		if req.Header.Get("If-None-Match") == etag {
+			res.WriteHeader(http.StatusNotModified)
+			return
+		}
+		res.Header().Set("Content-Type", "application/json")
+		res.Header().Set("ETag", etag)
+		fmt.Fprint(res, `{"features":[{"name":"`+name+`","feature_id":"cycle-rentals","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[],"segments":[]}`)
+	}))
+	defer server.Close()
+	update := func(newETag string, newName string) {
+		mu.Lock()
+		defer mu.Unlock()
+		etag = newETag
+		name = newName
+	}
+
+	dir := t.TempDir()
+	persistentCache := path.Join(dir, "appconfiguration.json")
+	ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", APIKey: "apikey"})
+	ch := ac.configurationHandlerInstance
+	ch.SetContext("collectionID", "environmentID", ContextOptions{LiveConfigUpdateEnabled: true, RetryPolicy: &noRetryPolicy, PersistentCacheDirectory: dir})
+	ch.urlBuilder.Init("collectionID", "environmentID", "region", "guid", "apikey", server.URL)
+	ch.urlBuilder.SetAuthenticator(&core.NoAuthAuthenticator{})
+	notifications := 0
+	ac.AddConfigurationUpdateListener(func(ChangeSet) {
+		notifications++
+	})
+
+	// the first fetch has no ETag to send
+	assert.Nil(t, ch.fetchFromAPI())
+	ch.backgroundTasks.Wait()
+	assert.Equal(t, 1, notifications)
+	_, err := os.Stat(persistentCache)
+	assert.Nil(t, err)
+	os.Remove(persistentCache)
+
+	// 304 Not Modified changes nothing
+	cache := ch.loadCache()
+	assert.Nil(t, ch.fetchFromAPI())
+	ch.backgroundTasks.Wait()
+	assert.Equal(t, 1, notifications)
+	assert.True(t, cache == ch.loadCache())
+	_, err = os.Stat(persistentCache)
+	assert.True(t, os.IsNotExist(err))
+
+	// a new ETag with the same configurations does not notify the listeners either
+	update(`"v2"`, "Cycle Rentals")
+	assert.Nil(t, ch.fetchFromAPI())
+	ch.backgroundTasks.Wait()
+	assert.Equal(t, 1, notifications)
+	assert.True(t, cache == ch.loadCache())
+	assert.Nil(t, ch.fetchFromAPI())
+
+	// changed configurations are loaded
+	update(`"v3"`, "Cycle Rentals v3")
+	assert.Nil(t, ch.fetchFromAPI())
+	ch.backgroundTasks.Wait()
+	assert.Equal(t, 2, notifications)
+	feature, _ := ch.getFeature("cycle-rentals")
+	assert.Equal(t, "Cycle Rentals v3", feature.Name)
+	_, err = os.Stat(persistentCache)
+	assert.Nil(t, err)
+	mu.Lock()
+	assert.Equal(t, []string{"", `"v1"`, `"v1"`, `"v2"`, `"v2"`}, ifNoneMatch)
+	mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(t, ac.Close(ctx))
+}
+
+func TestFetchConfigurationsSendsETag(t *testing.T) {
+	mockLogger()
+	var mu sync.Mutex
+	var ifNoneMatch []string
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		ifNoneMatch = append(ifNoneMatch, req.Header.Get("If-None-Match"))
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			res.WriteHeader(http.StatusNotModified)
+			return
+		}
+		res.Header().Set("Content-Type", "application/json")
+		res.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(res, `{"features":[{"name":"Cycle Rentals","feature_id":"cycle-rentals","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[],"segments":[]}`)
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	bootstrapFile := path.Join(dir, "bootstrap.json")
+	assert.Nil(t, ioutil.WriteFile(bootstrapFile, []byte(`{"features":[],"properties":[],"segments":[]}`), 0644))
+
+	ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", Authenticator: &core.NoAuthAuthenticator{}})
+	notifications := 0
+	ac.AddConfigurationUpdateListener(func(ChangeSet) {
+		notifications++
+	})
+	assert.Nil(t, ac.SetContext("c1", "dev", ContextOptions{
+		LiveConfigUpdateEnabled: true,
+		BootstrapFile:           bootstrapFile,
+		BaseURL:                 server.URL,
+		RetryPolicy:             &noRetryPolicy,
+		RefreshMode:             RefreshModeManual,
+	}))
+	ch := ac.configurationHandlerInstance
+	ch.backgroundTasks.Wait()
+	assert.Equal(t, 2, notifications)
+
+	// the manual refreshes neither reload the bootstrap file nor drop the ETag of the configurations in use
+	ac.FetchConfigurations()
+	ch.backgroundTasks.Wait()
+	ac.FetchConfigurations()
+	ch.backgroundTasks.Wait()
+	assert.Equal(t, 2, notifications)
+	feature, _ := ac.GetFeature("cycle-rentals")
+	assert.Equal(t, "Cycle Rentals", feature.Name)
+	mu.Lock()
+	assert.Equal(t, []string{"", `"v1"`, `"v1"`}, ifNoneMatch)
+	mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(t, ac.Close(ctx))
+}
+
+// refreshServer : Server counting the configuration fetches and the websocket upgrades, which it rejects
+type refreshServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	name     string
+	fetches  int
+	upgrades int
+}
+
+func newRefreshServer() *refreshServer {
+	rs := &refreshServer{name: "Cycle Rentals"}
+	rs.Server = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		rs.mu.Lock()
+		defer rs.mu.Unlock()
+		if websocket.IsWebSocketUpgrade(req) {
+			rs.upgrades++
+			res.WriteHeader(503)
+			return
+		}
+		rs.fetches++
+		res.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(res, `{"features":[{"name":"`+rs.name+`","feature_id":"cycle-rentals","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[],"segments":[]}`)
+	}))
+	return rs
+}
+
+func (rs *refreshServer) counts() (int, int) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return rs.fetches, rs.upgrades
+}
+
+func (rs *refreshServer) setName(name string) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.name = name
+}
+
+func TestRefreshMode(t *testing.T) {
+	mockLogger()
+	newClient := func(options ContextOptions) *AppConfiguration {
+		ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", Authenticator: &core.NoAuthAuthenticator{}})
+		assert.Nil(t, ac.SetContext("c1", "dev", options))
+		return ac
+	}
+	closeClient := func(ac *AppConfiguration) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		assert.Nil(t, ac.Close(ctx))
+	}
+
+	// an unknown refresh mode is rejected
+	ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", APIKey: "apikey"})
+	err := ac.SetContext("c1", "dev", ContextOptions{LiveConfigUpdateEnabled: true, RefreshMode: "push"})
+	assert.True(t, errors.Is(err, ErrInvalidRefreshMode))
+
+	// polling fetches the changes without a websocket
+	server := newRefreshServer()
+	defer server.Close()
+	ac = newClient(ContextOptions{LiveConfigUpdateEnabled: true, BaseURL: server.URL, RetryPolicy: &noRetryPolicy, RefreshMode: RefreshModePolling, PollingInterval: 10 * time.Millisecond})
+	changes := make(chan ChangeSet, 10)
+	ac.AddConfigurationUpdateListener(func(changeSet ChangeSet) {
+		changes <- changeSet
+	})
+	server.setName("Cycle Rentals v2")
+	select {
+	case changeSet := <-changes:
+		assert.Equal(t, ConfigurationSourcePolling, changeSet.Source)
+		assert.Equal(t, []string{"cycle-rentals"}, changeSet.Features.Modified)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Test failed: configurations not polled")
+	}
+	closeClient(ac)
+	_, upgrades := server.counts()
+	assert.Equal(t, 0, upgrades)
+
+	// the manual refresh mode fetches the configurations only once
+	server = newRefreshServer()
+	defer server.Close()
+	ac = newClient(ContextOptions{LiveConfigUpdateEnabled: true, BaseURL: server.URL, RetryPolicy: &noRetryPolicy, RefreshMode: RefreshModeManual, PollingInterval: 10 * time.Millisecond})
+	time.Sleep(50 * time.Millisecond)
+	closeClient(ac)
+	fetches, upgrades := server.counts()
+	assert.Equal(t, 1, fetches)
+	assert.Equal(t, 0, upgrades)
+
+	// polling takes over while the websocket cannot connect for longer than the threshold
+	server = newRefreshServer()
+	defer server.Close()
+	ac = newClient(ContextOptions{
+		LiveConfigUpdateEnabled:    true,
+		BaseURL:                    server.URL,
+		RetryPolicy:                &RetryPolicy{InitialDelay: 10 * time.Millisecond, MaxDelay: 10 * time.Millisecond, MaxAttempts: 100},
+		PollingInterval:            10 * time.Millisecond,
+		WebSocketFallbackThreshold: 20 * time.Millisecond,
+	})
+	deadline := time.Now().Add(5 * time.Second)
+	for fetches, upgrades = server.counts(); fetches < 3 && time.Now().Before(deadline); fetches, upgrades = server.counts() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	closeClient(ac)
+	assert.True(t, fetches >= 3)
+	assert.True(t, upgrades >= 1)
+}
+
+func TestWebSocketHeartbeat(t *testing.T) {
+	mockLogger()
+	var mu sync.Mutex
+	connections := 0
+	connected := make(chan struct{}, 10)
+	fetched := make(chan struct{}, 10)
+	pings := make(chan struct{}, 100)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if websocket.IsWebSocketUpgrade(req) {
+			upgrader := websocket.Upgrader{}
+			ws, err := upgrader.Upgrade(res, req, nil)
+			if err != nil {
+				return
+			}
+			defer ws.Close()
+			mu.Lock()
+			connections++
+			stale := connections == 1
+			mu.Unlock()
+			connected <- struct{}{}
+			if stale {
+				// a half-open connection: nothing is read, so the pings of the client are never answered
+				<-release
+				return
+			}
+			ws.SetPingHandler(func(data string) error {
+				select {
+				case pings <- struct{}{}:
+				default:
+				}
+				return ws.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+			})
+			// reading answers the pings of the client with pongs
+			for {
+				if _, _, err := ws.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}
+		res.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(res, `{"features":[],"properties":[],"segments":[]}`)
+		fetched <- struct{}{}
+	}))
+	defer server.Close()
+	defer close(release)
+	wait := func(events chan struct{}, n int, what string) {
+		for i := 0; i < n; i++ {
+			select {
+			case <-events:
+			case <-time.After(5 * time.Second):
+				t.Fatal("Test failed: " + what)
+			}
+		}
+	}
+
+	ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", Authenticator: &core.NoAuthAuthenticator{}})
+	err := ac.SetContext("c1", "dev", ContextOptions{
+		LiveConfigUpdateEnabled: true,
+		BaseURL:                 server.URL,
+		RetryPolicy:             &RetryPolicy{InitialDelay: 10 * time.Millisecond, MaxDelay: 10 * time.Millisecond, MaxAttempts: 100},
+		WebSocketPingInterval:   10 * time.Millisecond,
+		WebSocketMaxSilence:     100 * time.Millisecond,
+	})
+	assert.Nil(t, err)
+
+	// the stale connection is replaced, and the configurations are fetched again after reconnecting
+	wait(connected, 2, "stale web socket not replaced")
+	wait(fetched, 2, "configurations not fetched again after reconnecting")
+
+	// a connection answering the pings is kept, however long the server sends nothing: the pings of more than three
+	// times the max silence are answered on the same connection
+	wait(pings, 35, "web socket not pinged")
+	mu.Lock()
+	assert.Equal(t, 2, connections)
+	mu.Unlock()
+	assert.Equal(t, 0, len(connected))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(t, ac.Close(ctx))
+}
+
+func TestCoalesceWebSocketNotifications(t *testing.T) {
+	mockLogger()
+	var mu sync.Mutex
+	fetches, inFlight, maxInFlight := 0, 0, 0
+	counts := func() (int, int) {
+		mu.Lock()
+		defer mu.Unlock()
+		return fetches, maxInFlight
+	}
+	burst := make(chan int)
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if websocket.IsWebSocketUpgrade(req) {
+			upgrader := websocket.Upgrader{}
+			ws, err := upgrader.Upgrade(res, req, nil)
+			if err != nil {
+				return
+			}
+			defer ws.Close()
+			for n := range burst {
+				for i := 0; i < n; i++ {
+					ws.WriteMessage(websocket.TextMessage, []byte("configurations changed"))
+				}
+			}
+			return
+		}
+		mu.Lock()
+		fetches++
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		res.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(res, `{"features":[],"properties":[],"segments":[]}`)
+	}))
+	defer server.Close()
+	defer close(burst)
+
+	ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", Authenticator: &core.NoAuthAuthenticator{}})
+	ch := ac.configurationHandlerInstance
+	err := ac.SetContext("c1", "dev", ContextOptions{
+		LiveConfigUpdateEnabled: true,
+		BaseURL:                 server.URL,
+		RetryPolicy:             &noRetryPolicy,
+		WebSocketDebounceWindow: 50 * time.Millisecond,
+	})
+	assert.Nil(t, err)
+	waitForFetches := func(n int) {
+		deadline := time.Now().Add(5 * time.Second)
+		for f, _ := counts(); f < n && time.Now().Before(deadline); f, _ = counts() {
+			time.Sleep(10 * time.Millisecond)
+		}
+		// leave the time for an unexpected extra fetch to show up
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// a burst of notifications is served by a single fetch
+	burst <- 10
+	waitForFetches(2)
+	f, _ := counts()
+	assert.Equal(t, 2, f)
+
+	// and so is the next one
+	burst <- 5
+	waitForFetches(3)
+	f, _ = counts()
+	assert.Equal(t, 3, f)
+
+	// concurrent fetches are serialised
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ch.fetchFromAPI()
+		}()
+	}
+	wg.Wait()
+	_, m := counts()
+	assert.Equal(t, 1, m)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(t, ac.Close(ctx))
+}
+
+func TestStatus(t *testing.T) {
+	mockLogger()
+	var mu sync.Mutex
+	statusCode := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if statusCode != http.StatusOK {
+			res.WriteHeader(statusCode)
+			return
+		}
+		res.Header().Set("Content-Type", "application/json")
+		res.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(res, `{"features":[],"properties":[],"segments":[]}`)
+	}))
+	defer server.Close()
+	respondWith := func(code int) {
+		mu.Lock()
+		defer mu.Unlock()
+		statusCode = code
+	}
+
+	ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", Authenticator: &core.NoAuthAuthenticator{}})
+	ch := ac.configurationHandlerInstance
+	assert.Equal(t, Status{}, ac.Status())
+	var statuses []Status
+	lastStatus := func() (Status, int) {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(statuses) == 0 {
+			return Status{}, 0
+		}
+		return statuses[len(statuses)-1], len(statuses)
+	}
+	ac.OnStatusChange(func(Status) {
+		panic("a failing listener does not prevent the others from being called")
+	})
+	ac.OnStatusChange(func(status Status) {
+		mu.Lock()
+		defer mu.Unlock()
+		statuses = append(statuses, status)
+	})
+
+	// the configurations are fetched from the server
+	assert.Nil(t, ac.SetContext("c1", "dev", ContextOptions{LiveConfigUpdateEnabled: true, BaseURL: server.URL, RetryPolicy: &noRetryPolicy, RefreshMode: RefreshModeManual}))
+	ch.backgroundTasks.Wait()
+	status := ac.Status()
+	assert.Equal(t, ConfigurationSourceServer, status.Source)
+	assert.Equal(t, `"v1"`, status.ETag)
+	assert.True(t, strings.HasPrefix(status.Version, "sha256:"))
+	assert.False(t, status.LastSuccessfulFetch.IsZero())
+	assert.Nil(t, status.LastError)
+	assert.Equal(t, 0, status.ConsecutiveFailures)
+	assert.False(t, status.WebSocketConnected)
+	notified, _ := lastStatus()
+	assert.True(t, status.equal(notified))
+	fetchedAt := status.LastSuccessfulFetch
+
+	// failures are counted until the next successful fetch, which leaves the last error
+	respondWith(http.StatusInternalServerError)
+	assert.NotNil(t, ch.fetchFromAPI())
+	assert.NotNil(t, ch.fetchFromAPI())
+	status = ac.Status()
+	assert.Equal(t, 2, status.ConsecutiveFailures)
+	assert.True(t, errors.Is(status.LastError, ErrConfigFetchFailed))
+	assert.False(t, status.LastErrorTime.IsZero())
+	assert.Equal(t, fetchedAt, status.LastSuccessfulFetch)
+	assert.Equal(t, ConfigurationSourceServer, status.Source)
+	notified, _ = lastStatus()
+	assert.Equal(t, 2, notified.ConsecutiveFailures)
+
+	respondWith(http.StatusOK)
+	assert.Nil(t, ch.fetchFromAPI())
+	status = ac.Status()
+	assert.Equal(t, 0, status.ConsecutiveFailures)
+	assert.True(t, errors.Is(status.LastError, ErrConfigFetchFailed))
+	assert.True(t, status.LastSuccessfulFetch.After(fetchedAt))
+
+	// the listeners are called only when the status changes
+	ch.setWebSocketConnected(true)
+	notified, count := lastStatus()
+	assert.True(t, notified.WebSocketConnected)
+	assert.True(t, ac.Status().WebSocketConnected)
+	ch.setWebSocketConnected(true)
+	_, sameCount := lastStatus()
+	assert.Equal(t, count, sameCount)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(t, ac.Close(ctx))
+	notified, _ = lastStatus()
+	assert.False(t, notified.WebSocketConnected)
+	assert.False(t, ac.Status().WebSocketConnected)
+}
+
+func TestStatusListenerUsesClient(t *testing.T) {
+	mockLogger()
+	ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", Authenticator: &core.NoAuthAuthenticator{}})
+	ch := ac.configurationHandlerInstance
+	ac.isInitializedConfig = true
+	ch.SetContext("c1", "dev", ContextOptions{LiveConfigUpdateEnabled: false})
+
+	// the listener reads the status, subscribes and unsubscribes, and changes the status again
+	var statuses []Status
+	ac.OnStatusChange(func(status Status) {
+		statuses = append(statuses, ac.Status())
+		ac.OnStatusChange(func(Status) {})()
+		if status.WebSocketConnected {
+			ch.setWebSocketConnected(false)
+		}
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ch.setWebSocketConnected(true)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Test failed: status change listener deadlocked")
+	}
+	if assert.Equal(t, 2, len(statuses)) {
+		assert.False(t, statuses[1].WebSocketConnected)
+	}
+}
+
+func TestStatusSourceConfirmedByServer(t *testing.T) {
+	mockLogger()
+	data := `{"features":[{"name":"Cycle Rentals","feature_id":"cycle-rentals","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[],"segments":[]}`
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			res.WriteHeader(http.StatusNotModified)
+			return
+		}
+		res.Header().Set("Content-Type", "application/json")
+		res.Header().Set("ETag", `"v2"`)
+		fmt.Fprint(res, data)
+	}))
+	defer server.Close()
+	newClient := func(options ContextOptions) *AppConfiguration {
+		ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", Authenticator: &core.NoAuthAuthenticator{}})
+		options.LiveConfigUpdateEnabled = true
+		options.BaseURL = server.URL
+		options.RetryPolicy = &noRetryPolicy
+		options.RefreshMode = RefreshModeManual
+		assert.Nil(t, ac.SetContext("c1", "dev", options))
+		ac.configurationHandlerInstance.backgroundTasks.Wait()
+		return ac
+	}
+	closeClient := func(ac *AppConfiguration) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		assert.Nil(t, ac.Close(ctx))
+	}
+
+	// the persistent cache answered by 304 Not Modified
+	dir := t.TempDir()
+	persistentCache, _ := json.Marshal(utils.NewPersistentCache("guid", "c1", "dev", `"v1"`, time.Now(), []byte(data)))
+	assert.Nil(t, ioutil.WriteFile(path.Join(dir, "appconfiguration.json"), persistentCache, 0644))
+	var statuses []Status
+	ac := newClient(ContextOptions{PersistentCacheDirectory: dir})
+	status := ac.Status()
+	assert.Equal(t, ConfigurationSourceServer, status.Source)
+	assert.Equal(t, `"v1"`, status.ETag)
+	ac.OnStatusChange(func(status Status) {
+		statuses = append(statuses, status)
+	})
+	ac.FetchConfigurations()
+	ac.configurationHandlerInstance.backgroundTasks.Wait()
+	assert.Equal(t, 1, len(statuses))
+	assert.Equal(t, ConfigurationSourceServer, statuses[0].Source)
+	closeClient(ac)
+
+	// the bootstrap file with the configurations of the server
+	bootstrapFile := path.Join(t.TempDir(), "bootstrap.json")
+	assert.Nil(t, ioutil.WriteFile(bootstrapFile, []byte(data), 0644))
+	ac = newClient(ContextOptions{BootstrapFile: bootstrapFile})
+	status = ac.Status()
+	assert.Equal(t, ConfigurationSourceServer, status.Source)
+	assert.Equal(t, `"v2"`, status.ETag)
+	closeClient(ac)
+}
+
+func TestSameConfigurationsNotNotified(t *testing.T) {
+	mockLogger()
+	data := `{"features":[{"name":"Cycle Rentals","feature_id":"cycle-rentals","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[],"segments":[]}`
+	var mu sync.Mutex
+	available := false
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !available {
+			res.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		res.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(res, data)
+	}))
+	defer server.Close()
+
+	// the bootstrap file holds the configurations of the server, indented and with the keys in another order
+	bootstrapFile := path.Join(t.TempDir(), "bootstrap.json")
+	indented := `{
+  "segments": [],
+  "properties": [],
+  "features": [
+    {
+      "feature_id": "cycle-rentals",
+      "name": "Cycle Rentals",
+      "enabled": true,
+      "type": "BOOLEAN",
+      "disabled_value": false,
+      "enabled_value": true,
+      "segment_rules": []
+    }
+  ]
+}`
+	assert.Nil(t, ioutil.WriteFile(bootstrapFile, []byte(indented), 0644))
+	ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", Authenticator: &core.NoAuthAuthenticator{}})
+	// the server is not reached, so the configurations of the bootstrap file are used
+	err := ac.SetContext("c1", "dev", ContextOptions{LiveConfigUpdateEnabled: true, BaseURL: server.URL, BootstrapFile: bootstrapFile, RetryPolicy: &noRetryPolicy, RefreshMode: RefreshModeManual})
+	assert.True(t, errors.Is(err, ErrConfigFetchFailed))
+	ch := ac.configurationHandlerInstance
+	assert.Equal(t, ConfigurationSourceBootstrapFile, ac.Status().Source)
+
+	// fetching the same configurations from the server notifies neither the listeners nor the watchers
+	var changeSets []ChangeSet
+	ac.AddConfigurationUpdateListener(func(changeSet ChangeSet) {
+		changeSets = append(changeSets, changeSet)
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	watch := ac.WatchFeature(ctx, "cycle-rentals")
+	<-watch
+	mu.Lock()
+	available = true
+	mu.Unlock()
+	assert.Nil(t, ch.fetchFromAPI())
+	assert.Equal(t, 0, len(changeSets))
+	select {
+	case feature := <-watch:
+		t.Errorf("Test failed: watcher notified of %s", feature.GetFeatureID())
+	default:
+	}
+	assert.Equal(t, ConfigurationSourceServer, ac.Status().Source)
+	assert.Nil(t, ac.Close(ctx))
+}
+
+// memoryMetricsRecorder : Metrics recorder keeping the measurements in memory
+type memoryMetricsRecorder struct {
+	mu                     sync.Mutex
+	evaluations            map[string]int
+	fetches                int
+	fetchFailures          int
+	webSocketReconnects    int
+	cacheScope             [2]string
+	cacheSize              [3]int
+	onCacheSize            func()
+	listenerLatencies      int
+	meteringBatchesSent    int
+	meteringBatchesDropped int
+}
+
+func (r *memoryMetricsRecorder) RecordFeatureEvaluation(featureID string, segmentID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.evaluations["feature/"+featureID+"/"+segmentID]++
+}
+
+func (r *memoryMetricsRecorder) RecordPropertyEvaluation(propertyID string, segmentID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.evaluations["property/"+propertyID+"/"+segmentID]++
+}
+
+func (r *memoryMetricsRecorder) RecordFetch(duration time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fetches++
+	if err != nil {
+		r.fetchFailures++
+	}
+}
+
+func (r *memoryMetricsRecorder) RecordWebSocketReconnect() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.webSocketReconnects++
+}
+
+func (r *memoryMetricsRecorder) RecordCacheSize(collectionID string, environmentID string, features int, properties int, segments int) {
+	if r.onCacheSize != nil {
+		r.onCacheSize()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cacheScope = [2]string{collectionID, environmentID}
+	r.cacheSize = [3]int{features, properties, segments}
+}
+
+func (r *memoryMetricsRecorder) RecordListenerLatency(duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listenerLatencies++
+}
+
+func (r *memoryMetricsRecorder) RecordMeteringBatchSent() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.meteringBatchesSent++
+}
+
+func (r *memoryMetricsRecorder) RecordMeteringBatchDropped() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.meteringBatchesDropped++
+}
+
+func TestMetricsRecorder(t *testing.T) {
+	mockLogger()
+	var mu sync.Mutex
+	statusCode := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if statusCode != http.StatusOK {
+			res.WriteHeader(statusCode)
+			return
+		}
+		res.Header().Set("Content-Type", "application/json")
+		if req.Method == http.MethodPost {
+			fmt.Fprint(res, `{}`)
+			return
+		}
+		fmt.Fprint(res, `{"features":[{"name":"Cycle Rentals","feature_id":"cycle-rentals","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[{"rules":[{"segments":["ka761hap"]}],"value":false,"order":1}],"enabled":true}],"properties":[{"name":"Show Ad","property_id":"show-ad","type":"BOOLEAN","value":false,"segment_rules":[]}],"segments":[{"name":"ibm employees","segment_id":"ka761hap","rules":[{"values":["ibm.com"],"operator":"endsWith","attribute_name":"email"}]}]}`)
+	}))
+	defer server.Close()
+	respondWith := func(code int) {
+		mu.Lock()
+		defer mu.Unlock()
+		statusCode = code
+	}
+
+	recorder := &memoryMetricsRecorder{evaluations: make(map[string]int)}
+	ac, _ := NewClient(ClientOptions{Region: "us-south", GUID: "guid", Authenticator: &core.NoAuthAuthenticator{}})
+	ch := ac.configurationHandlerInstance
+	ac.AddConfigurationUpdateListener(func(ChangeSet) {})
+	// the recorder is called without the lock of the client held
+	recorder.onCacheSize = func() {
+		ac.Status()
+	}
+	assert.Nil(t, ac.SetContext("c1", "dev", ContextOptions{
+		LiveConfigUpdateEnabled: true,
+		BaseURL:                 server.URL,
+		RetryPolicy:             &noRetryPolicy,
+		RefreshMode:             RefreshModeManual,
+		MetricsRecorder:         recorder,
+	}))
+	ch.backgroundTasks.Wait()
+
+	// the evaluations are recorded with the segment that matched
+	feature, err := ac.GetFeature("cycle-rentals")
+	assert.Nil(t, err)
+	feature.GetCurrentValue("user1", map[string]interface{}{"email": "user1@ibm.com"})
+	feature.GetCurrentValue("user2", map[string]interface{}{"email": "user2@example.com"})
+	property, err := ac.GetProperty("show-ad")
+	assert.Nil(t, err)
+	property.GetCurrentValue("user1", nil)
+
+	// a failed fetch is recorded
+	respondWith(http.StatusInternalServerError)
+	assert.NotNil(t, ch.fetchFromAPI())
+
+	// the usage data is sent when the client is closed
+	respondWith(http.StatusOK)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(t, ac.Close(ctx))
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	assert.Equal(t, map[string]int{
+		"feature/cycle-rentals/ka761hap": 1,
+		"feature/cycle-rentals/":         1,
+		"property/show-ad/":              1,
+	}, recorder.evaluations)
+	assert.Equal(t, 2, recorder.fetches)
+	assert.Equal(t, 1, recorder.fetchFailures)
+	assert.Equal(t, [2]string{"c1", "dev"}, recorder.cacheScope)
+	assert.Equal(t, [3]int{1, 1, 1}, recorder.cacheSize)
+	assert.Equal(t, 1, recorder.listenerLatencies)
+	assert.Equal(t, 0, recorder.webSocketReconnects)
+	assert.Equal(t, 2, recorder.meteringBatchesSent)
+	assert.Equal(t, 0, recorder.meteringBatchesDropped)
 }
 
 func TestConfigHandlerGetProperty(t *testing.T) {
 	// when property id exists in the cache
 	ch := GetConfigurationHandlerInstance()
 	data := `{"features":[{"name":"Cycle Rentals8","feature_id":"cycle-rentals8","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[{"name":"ShowAd","property_id":"show-ad","tags":"","type":"BOOLEAN","value":false,"segment_rules":[],"created_time":"2021-05-26T06:23:18Z","updated_time":"2021-06-08T03:38:38Z","evaluation_time":"2021-06-03T10:08:46Z"}],"segments":[{"name":"beta-users","segment_id":"knliu818","rules":[{"values":["ibm.com"],"operator":"contains","attribute_name":"email"}]},{"name":"ibm employees","segment_id":"ka761hap","rules":[{"values":["ibm.com","in.ibm.com"],"operator":"endsWith","attribute_name":"email"}]}]}`
-	ch.saveInCache([]byte(data))
+	ch.saveInCache([]byte(data), ConfigurationSourceServer)
 	val, _ := ch.getProperty("show-ad")
 	assert.Equal(t, "ShowAd", val.Name)
 
@@ -276,7 +1621,7 @@ func TestConfigHandlerGetProperty(t *testing.T) {
 
 	// when cache is empty
 	data = `{"Features":null,"Properties":null,"Collection":{"name":"","collection_id":""},"Segments":null}`
-	ch.saveInCache([]byte(data))
+	ch.saveInCache([]byte(data), ConfigurationSourceServer)
 	val, err = ch.getProperty("show-ad")
 	assert.Equal(t, "", val.Name)
 	assert.Equal(t, "error : invalid property id show-ad", fmt.Sprint(err))
@@ -287,12 +1632,12 @@ func TestConfigHandlerGetProperties(t *testing.T) {
 	// when property id exists in the cache
 	ch := GetConfigurationHandlerInstance()
 	data := `{"features":[{"name":"Cycle Rentals8","feature_id":"cycle-rentals8","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[{"name":"ShowAd","property_id":"show-ad","tags":"","type":"BOOLEAN","value":false,"segment_rules":[],"created_time":"2021-05-26T06:23:18Z","updated_time":"2021-06-08T03:38:38Z","evaluation_time":"2021-06-03T10:08:46Z"}],"segments":[{"name":"beta-users","segment_id":"knliu818","rules":[{"values":["ibm.com"],"operator":"contains","attribute_name":"email"}]},{"name":"ibm employees","segment_id":"ka761hap","rules":[{"values":["ibm.com","in.ibm.com"],"operator":"endsWith","attribute_name":"email"}]}]}`
-	ch.saveInCache([]byte(data))
+	ch.saveInCache([]byte(data), ConfigurationSourceServer)
 	val, _ := ch.getProperties()
 	assert.Equal(t, "ShowAd", val["show-ad"].Name)
 
 	// when cache is
-	ch.cache = nil
+	ch.storeCache(nil)
 	val, _ = ch.getProperties()
 	assert.Equal(t, 0, len(val))
 
@@ -302,7 +1647,7 @@ func TestConfigHandlerGetFeature(t *testing.T) {
 	// when property id exists in the cache
 	ch := GetConfigurationHandlerInstance()
 	data := `{"features":[{"name":"Cycle Rentals8","feature_id":"cycle-rentals8","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[{"name":"ShowAd","property_id":"show-ad","tags":"","type":"BOOLEAN","value":false,"segment_rules":[],"created_time":"2021-05-26T06:23:18Z","updated_time":"2021-06-08T03:38:38Z","evaluation_time":"2021-06-03T10:08:46Z"}],"segments":[{"name":"beta-users","segment_id":"knliu818","rules":[{"values":["ibm.com"],"operator":"contains","attribute_name":"email"}]},{"name":"ibm employees","segment_id":"ka761hap","rules":[{"values":["ibm.com","in.ibm.com"],"operator":"endsWith","attribute_name":"email"}]}]}`
-	ch.saveInCache([]byte(data))
+	ch.saveInCache([]byte(data), ConfigurationSourceServer)
 	val, _ := ch.getFeature("cycle-rentals8")
 	assert.Equal(t, "Cycle Rentals8", val.Name)
 
@@ -313,7 +1658,7 @@ func TestConfigHandlerGetFeature(t *testing.T) {
 
 	// when cache is empty
 	data = `{"Features":null,"Properties":null,"Collection":{"name":"","collection_id":""},"Segments":null}`
-	ch.saveInCache([]byte(data))
+	ch.saveInCache([]byte(data), ConfigurationSourceServer)
 	val, err = ch.getFeature("cycle-rentals8")
 	assert.Equal(t, "", val.Name)
 	assert.Equal(t, "error : invalid feature id cycle-rentals8", fmt.Sprint(err))
@@ -323,12 +1668,12 @@ func TestConfigHandlerGetFeatures(t *testing.T) {
 	// when property id exists in the cache
 	ch := GetConfigurationHandlerInstance()
 	data := `{"features":[{"name":"Cycle Rentals8","feature_id":"cycle-rentals8","type":"BOOLEAN","enabled_value":true,"disabled_value":false,"segment_rules":[],"enabled":true}],"properties":[{"name":"ShowAd","property_id":"show-ad","tags":"","type":"BOOLEAN","value":false,"segment_rules":[],"created_time":"2021-05-26T06:23:18Z","updated_time":"2021-06-08T03:38:38Z","evaluation_time":"2021-06-03T10:08:46Z"}],"segments":[{"name":"beta-users","segment_id":"knliu818","rules":[{"values":["ibm.com"],"operator":"contains","attribute_name":"email"}]},{"name":"ibm employees","segment_id":"ka761hap","rules":[{"values":["ibm.com","in.ibm.com"],"operator":"endsWith","attribute_name":"email"}]}]}`
-	ch.saveInCache([]byte(data))
+	ch.saveInCache([]byte(data), ConfigurationSourceServer)
 	val, _ := ch.getFeatures()
 	assert.Equal(t, "Cycle Rentals8", val["cycle-rentals8"].Name)
 
 	// when cache is nil
-	ch.cache = nil
+	ch.storeCache(nil)
 	val, _ = ch.getFeatures()
 	assert.Equal(t, 0, len(val))
 
@@ -352,6 +1697,33 @@ func wsEndpoint(w http.ResponseWriter, r *http.Request) {
 	}
 
 }
+
+// fakeClock : Clock firing every timer at once, except the ones of at least blockFrom when it is set, and recording the
+// delays waited for
+type fakeClock struct {
+	delays    []time.Duration
+	blockFrom time.Duration
+	mu        sync.Mutex
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.delays = append(c.delays, d)
+	if c.blockFrom > 0 && d >= c.blockFrom {
+		return make(chan time.Time)
+	}
+	fired := make(chan time.Time, 1)
+	fired <- time.Time{}
+	return fired
+}
+
+func (c *fakeClock) getDelays() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.delays...)
+}
+
 func resetConfigurationHandler(ch *ConfigurationHandler) {
-	ch.cache = new(models.Cache)
+	ch.storeCache(new(models.Cache))
 }
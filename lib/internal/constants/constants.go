@@ -22,8 +22,11 @@ const DefaultSegmentID = "$$null$$"
 // DefaultUsageLimit : Default Usage Limit
 const DefaultUsageLimit = 25
 
+// SDKVersion : Version of the SDK
+const SDKVersion = "0.2.1"
+
 // UserAgent specifies the user agent name
-const UserAgent = "appconfiguration-go-sdk/0.2.1"
+const UserAgent = "appconfiguration-go-sdk/" + SDKVersion
 
 // ConfigurationFile : Name of file to which configurations will be written
 const ConfigurationFile = "appconfiguration.json"
@@ -25,6 +25,18 @@ const GUIDError = "Provide a valid guid."
 // ApikeyError : ApikeyError const
 const ApikeyError = "Provide a valid apiKey."
 
+// UnknownRegionError : UnknownRegionError const
+const UnknownRegionError = "Provide a supported region, or the BaseURL of the service in the context options."
+
+// EndpointError : EndpointError const
+const EndpointError = "Provide a valid endpoint. Use the public or the private endpoint."
+
+// BaseURLError : BaseURLError const
+const BaseURLError = "Provide a valid BaseURL with the http or https scheme and a valid WebSocketBaseURL with the ws or wss scheme."
+
+// AuthenticatorError : AuthenticatorError const
+const AuthenticatorError = "Provide a valid authenticator."
+
 // CollectionIDValueError : CollectionIDValueError const
 const CollectionIDValueError = "Provide a valid collectionId."
 
@@ -130,6 +142,51 @@ const WriteFileErr = "Error while writing to a file "
 // ReadFileErr : ReadFileErr const
 const ReadFileErr = "Error while reading file "
 
+// InvalidJSONFileErr : InvalidJSONFileErr const
+const InvalidJSONFileErr = "The file is not a valid json and is ignored "
+
+// PersistentCacheIgnored : PersistentCacheIgnored const
+const PersistentCacheIgnored = "The persistent cache could not be loaded and is ignored. "
+
+// PersistentCacheFormatError : PersistentCacheFormatError const
+const PersistentCacheFormatError = "The persistent cache is not in the expected format"
+
+// PersistentCacheChecksumError : PersistentCacheChecksumError const
+const PersistentCacheChecksumError = "The checksum of the persistent cache does not match its configurations"
+
+// PersistentCacheContextMismatch : PersistentCacheContextMismatch const
+const PersistentCacheContextMismatch = "The persistent cache belongs to another guid, collection or environment"
+
+// PersistentCacheExpired : PersistentCacheExpired const
+const PersistentCacheExpired = "The persistent cache is older than the max age"
+
+// ConfigurationsNotModified : ConfigurationsNotModified const
+const ConfigurationsNotModified = "The configurations on the server have not changed since they were last fetched."
+
+// PollingStarted : PollingStarted const
+const PollingStarted = "Polling the configurations every "
+
+// PollingConfigurations : PollingConfigurations const
+const PollingConfigurations = "Polling the configurations."
+
+// RefreshModeError : RefreshModeError const
+const RefreshModeError = "Provide a valid refresh mode. Use the websocket, polling or manual refresh mode."
+
+// WebSocketReconnected : WebSocketReconnected const
+const WebSocketReconnected = "Web socket reconnected. Fetching the configurations changed while it was disconnected."
+
+// WebSocketPingError : WebSocketPingError const
+const WebSocketPingError = "Error while pinging the web socket "
+
+// WebSocketNotificationCoalesced : WebSocketNotificationCoalesced const
+const WebSocketNotificationCoalesced = "Web socket notification coalesced with the pending fetch of the configurations."
+
+// StatusChangeListenerError : StatusChangeListenerError const
+const StatusChangeListenerError = "Status change listener failed: "
+
+// StatusChangeListenerMethodError : StatusChangeListenerMethodError const
+const StatusChangeListenerMethodError = "Status change listener should be a method or a function."
+
 // StoreFile : StoreFile const
 const StoreFile = "Storing file."
 
@@ -234,3 +291,45 @@ const TypeCastingError = "Error Type casting. Check the feature or property valu
 
 // ContextOptionsParameterDeprecation = Deprecation message
 const ContextOptionsParameterDeprecation = "Deprecated: With v0.2.1 the existing method of passing ConfigurationFile will be deprecated & removed from v0.3.0 \nUse BootstrapFile parameter instead."
+
+// BootstrapFileWatchStarted : BootstrapFileWatchStarted const
+const BootstrapFileWatchStarted = "Watching the bootstrap file for changes: "
+
+// BootstrapFileChanged : BootstrapFileChanged const
+const BootstrapFileChanged = "Reloading the changed bootstrap file: "
+
+// BootstrapFileReloadError : BootstrapFileReloadError const
+const BootstrapFileReloadError = "Failed to reload the bootstrap file. The previous configurations are kept in use. "
+
+// BootstrapFileKeyMissing : BootstrapFileKeyMissing const
+const BootstrapFileKeyMissing = "the bootstrap file has no top-level key "
+
+// BootstrapFileReadError : BootstrapFileReadError const
+const BootstrapFileReadError = "Failed to read the bootstrap file"
+
+// ConfigurationParseError : ConfigurationParseError const
+const ConfigurationParseError = "Failed to parse the configurations"
+
+// ConfigurationsNotReady : ConfigurationsNotReady const
+const ConfigurationsNotReady = "Configurations were not loaded from the server, the persistent cache or the bootstrap file in time"
+
+// ConfigurationsReady : ConfigurationsReady const
+const ConfigurationsReady = "Configurations are ready. Loaded from "
+
+// StopSendingMeteringData : StopSendingMeteringData const
+const StopSendingMeteringData = "Stop sending metering data in the background, sending metering data if any."
+
+// ClosingClient : ClosingClient const
+const ClosingClient = "Closing App Configuration client."
+
+// WrongDataTypeError : WrongDataTypeError const
+const WrongDataTypeError = "Data type of the feature or property does not match the requested type"
+
+// WrongDataFormatError : WrongDataFormatError const
+const WrongDataFormatError = "Data format of the feature or property does not match the requested format"
+
+// EvaluationError : EvaluationError const
+const EvaluationError = "Failed to evaluate the feature or property"
+
+// EvaluationContextMissingError : EvaluationContextMissingError const
+const EvaluationContextMissingError = "No evaluation context is attached to the context"
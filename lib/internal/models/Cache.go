@@ -19,6 +19,7 @@ package models
 import (
 	"os"
 
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils"
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
 )
 
@@ -27,6 +28,7 @@ type Cache struct {
 	FeatureMap  map[string]Feature
 	PropertyMap map[string]Property
 	SegmentMap  map[string]Segment
+	metering    *utils.Metering
 }
 
 // CacheInstance : Cache Instance
@@ -54,3 +56,43 @@ func SetCache(featureMap map[string]Feature, propertyMap map[string]Property, se
 func GetCacheInstance() *Cache {
 	return CacheInstance
 }
+
+// NewCache : Create a cache which is not shared through CacheInstance.
+// The features and properties in it evaluate segments from this cache and record their evaluations on the given metering instance.
+func NewCache(featureMap map[string]Feature, propertyMap map[string]Property, segmentMap map[string]Segment, metering *utils.Metering) *Cache {
+	cache := &Cache{
+		FeatureMap:  featureMap,
+		PropertyMap: propertyMap,
+		SegmentMap:  segmentMap,
+		metering:    metering,
+	}
+	for featureID, feature := range featureMap {
+		feature.cache = cache
+		featureMap[featureID] = feature
+	}
+	for propertyID, property := range propertyMap {
+		property.cache = cache
+		propertyMap[propertyID] = property
+	}
+	log.Debug(cache)
+	return cache
+}
+
+// getSegmentMap : Get the segments of the cache a feature or property was read from. Only features and properties
+// created outside of a cache fall back to the segments of CacheInstance.
+func getSegmentMap(cache *Cache) map[string]Segment {
+	if cache != nil {
+		return cache.SegmentMap
+	}
+	if cacheInstance := GetCacheInstance(); cacheInstance != nil {
+		return cacheInstance.SegmentMap
+	}
+	return nil
+}
+
+func getMetering(cache *Cache) *utils.Metering {
+	if cache != nil && cache.metering != nil {
+		return cache.metering
+	}
+	return utils.GetMeteringInstance()
+}
@@ -17,11 +17,25 @@
 package models
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/messages"
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
 	"gopkg.in/yaml.v3"
 )
 
+// ErrWrongDataType : Returned by the typed value getters when the data type of the feature or property is not the requested one
+var ErrWrongDataType = errors.New(messages.WrongDataTypeError)
+
+// ErrWrongDataFormat : Returned by the typed value getters when the data format of the feature or property is not the requested one
+var ErrWrongDataFormat = errors.New(messages.WrongDataFormatError)
+
+// ErrEvaluationFailed : Returned by the typed value getters when the feature or property could not be evaluated
+var ErrEvaluationFailed = errors.New(messages.EvaluationError)
+
 func IsValidDataType(category string) bool {
 	switch category {
 	case
@@ -70,3 +84,96 @@ func getTypeCastedValue(val interface{}, valType string, valFormat string) inter
 		return nil
 	}
 }
+
+func checkDataType(dataType string, dataFormat string, expectedType string, expectedFormat string) error {
+	if dataType != expectedType {
+		return fmt.Errorf("%w: %s is not %s", ErrWrongDataType, dataType, expectedType)
+	}
+	if len(expectedFormat) > 0 && dataFormat != expectedFormat {
+		return fmt.Errorf("%w: %s is not %s", ErrWrongDataFormat, dataFormat, expectedFormat)
+	}
+	return nil
+}
+
+func typeCastingError(val interface{}) error {
+	log.Error(messages.TypeCastingError)
+	return fmt.Errorf("%w: %s %v", ErrEvaluationFailed, messages.TypeCastingError, val)
+}
+
+func asBool(val interface{}, err error, defaultValue bool) (bool, error) {
+	if err != nil {
+		return defaultValue, err
+	}
+	if result, ok := val.(bool); ok {
+		return result, nil
+	}
+	return defaultValue, typeCastingError(val)
+}
+
+func asString(val interface{}, err error, defaultValue string) (string, error) {
+	if err != nil {
+		return defaultValue, err
+	}
+	if result, ok := val.(string); ok {
+		return result, nil
+	}
+	return defaultValue, typeCastingError(val)
+}
+
+func asFloat64(val interface{}, err error, defaultValue float64) (float64, error) {
+	if err != nil {
+		return defaultValue, err
+	}
+	if isNumber(val) {
+		return getFloat(val)
+	}
+	return defaultValue, typeCastingError(val)
+}
+
+func asInt(val interface{}, err error, defaultValue int) (int, error) {
+	result, err := asFloat64(val, err, float64(defaultValue))
+	if err != nil {
+		return defaultValue, err
+	}
+	// numeric values come as float64 from the json, only whole numbers are converted
+	if result != math.Trunc(result) {
+		return defaultValue, typeCastingError(val)
+	}
+	return int(result), nil
+}
+
+func decodeJSON(val interface{}, err error, into interface{}) error {
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(val)
+	if err != nil {
+		log.Error(messages.MarshalJSONErr, err)
+		return fmt.Errorf("%w: %v", ErrEvaluationFailed, err)
+	}
+	if err = json.Unmarshal(data, into); err != nil {
+		log.Error(messages.UnmarshalJSONErr, err)
+		return fmt.Errorf("%w: %v", ErrEvaluationFailed, err)
+	}
+	return nil
+}
+
+func decodeYAML(val interface{}, err error, into interface{}) error {
+	if err != nil {
+		return err
+	}
+	// the value is a yaml string, unless it was already parsed into a map by getTypeCastedValue
+	data, ok := val.(string)
+	if !ok {
+		marshalled, err := yaml.Marshal(val)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrEvaluationFailed, err)
+		}
+		data = string(marshalled)
+	}
+	if err := yaml.Unmarshal([]byte(data), into); err != nil {
+		log.Error(messages.UnmarshalYAMLErr, err)
+		return fmt.Errorf("%w: %v", ErrEvaluationFailed, err)
+	}
+	return nil
+}
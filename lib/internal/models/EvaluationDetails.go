@@ -0,0 +1,121 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"sort"
+
+	messages "github.com/IBM/appconfiguration-go-sdk/lib/internal/messages"
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
+)
+
+// EvaluationReason : Reason for the value of an evaluation
+type EvaluationReason string
+
+const (
+	// EvaluationReasonDisabled : The feature is disabled, its disabled value is returned
+	EvaluationReasonDisabled EvaluationReason = "DISABLED"
+	// EvaluationReasonDefaultNoRules : There are no segment rules, the enabled value of the feature or the value of the property is returned
+	EvaluationReasonDefaultNoRules EvaluationReason = "DEFAULT_NO_RULES"
+	// EvaluationReasonSegmentMatch : The entity belongs to a segment, the value of the segment rule is returned
+	EvaluationReasonSegmentMatch EvaluationReason = "SEGMENT_MATCH"
+	// EvaluationReasonSegmentMatchDefaultValue : The entity belongs to a segment whose rule uses the default value, which is returned
+	EvaluationReasonSegmentMatchDefaultValue EvaluationReason = "SEGMENT_MATCH_DEFAULT_VALUE"
+	// EvaluationReasonNoSegmentMatched : The entity belongs to none of the segments, the enabled value of the feature or the value of the property is returned
+	EvaluationReasonNoSegmentMatched EvaluationReason = "NO_SEGMENT_MATCHED"
+	// EvaluationReasonInvalid : The entity id is empty or the feature or property is incomplete, no value is returned
+	EvaluationReasonInvalid EvaluationReason = "INVALID"
+	// EvaluationReasonTypeError : The value can not be type casted to the data type and format of the feature or property
+	EvaluationReasonTypeError EvaluationReason = "TYPE_ERROR"
+)
+
+// RuleEvaluation : Result of evaluating one rule of a segment against the entity attributes
+type RuleEvaluation struct {
+	AttributeName  string
+	Operator       string
+	Values         []interface{}
+	AttributeValue interface{}
+	AttributeFound bool
+	Result         bool
+}
+
+// SegmentEvaluation : Result of evaluating a segment of a segment rule against the entity attributes
+type SegmentEvaluation struct {
+	SegmentID   string
+	SegmentName string
+	RuleOrder   int
+	Found       bool
+	Matched     bool
+	Rules       []RuleEvaluation
+}
+
+// EvaluationDetails : Value of an evaluation together with the reason for it.
+// Trace lists, in the order they were evaluated, the segments evaluated before the matching one (which is the last).
+type EvaluationDetails struct {
+	Value       interface{}
+	Reason      EvaluationReason
+	SegmentID   string
+	SegmentName string
+	RuleOrder   int
+	Trace       []SegmentEvaluation
+}
+
+// matchSegmentRules : Find, in ascending order of the rules, the first segment the entity belongs to.
+// The evaluation of every segment is recorded in the trace of the details when trace is true.
+func matchSegmentRules(rulesMap map[int]SegmentRule, segmentMap map[string]Segment, entityAttributes map[string]interface{}, details *EvaluationDetails, trace bool) (SegmentRule, bool) {
+	// sort the map elements as per ascending order of keys
+	var keys []int
+	for k := range rulesMap {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+
+	// after sorting , pick up each map element as per keys order
+	for _, k := range keys {
+		segmentRule := rulesMap[k]
+		for _, rule := range segmentRule.GetRules() {
+			for _, segmentKey := range rule.Segments {
+				log.Debug(messages.EvaluatingSegments)
+				segment, ok := segmentMap[segmentKey]
+				var matched bool
+				var ruleEvaluations []RuleEvaluation
+				if ok && trace {
+					matched, ruleEvaluations = segment.evaluateRuleWithTrace(entityAttributes)
+				} else if ok {
+					matched = segment.EvaluateRule(entityAttributes)
+				}
+				if trace {
+					details.Trace = append(details.Trace, SegmentEvaluation{
+						SegmentID:   segmentKey,
+						SegmentName: segment.GetName(),
+						RuleOrder:   segmentRule.GetOrder(),
+						Found:       ok,
+						Matched:     matched,
+						Rules:       ruleEvaluations,
+					})
+				}
+				if matched {
+					details.SegmentID = segmentKey
+					details.SegmentName = segment.GetName()
+					details.RuleOrder = segmentRule.GetOrder()
+					return segmentRule, true
+				}
+			}
+		}
+	}
+	return SegmentRule{}, false
+}
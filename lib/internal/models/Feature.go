@@ -21,7 +21,8 @@ import (
 	messages "github.com/IBM/appconfiguration-go-sdk/lib/internal/messages"
 	utils "github.com/IBM/appconfiguration-go-sdk/lib/internal/utils"
 
-	"sort"
+	"fmt"
+	"reflect"
 
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
 )
@@ -36,6 +37,13 @@ type Feature struct {
 	DisabledValue interface{}   `json:"disabled_value"`
 	SegmentRules  []SegmentRule `json:"segment_rules"`
 	Enabled       bool          `json:"enabled"`
+	cache         *Cache
+}
+
+// Equal : Check whether the feature has the same configuration as other, regardless of the cache they belong to
+func (f Feature) Equal(other Feature) bool {
+	f.cache, other.cache = nil, nil
+	return reflect.DeepEqual(f, other)
 }
 
 // GetFeatureName : Get Feature Name
@@ -107,60 +115,125 @@ func (f *Feature) GetCurrentValue(entityID string, entityAttributes map[string]i
 func (f *Feature) isFeatureValid() bool {
 	return !(f.Name == "" || f.FeatureID == "" || f.DataType == "" || f.EnabledValue == nil || f.DisabledValue == nil)
 }
+
+// GetBoolValue : Get the current value of a BOOLEAN feature.
+// Returns defaultValue and ErrWrongDataType when the feature is of another data type, or ErrEvaluationFailed when it cannot be evaluated.
+func (f *Feature) GetBoolValue(entityID string, entityAttributes map[string]interface{}, defaultValue bool) (bool, error) {
+	val, err := f.evaluateAs(entityID, entityAttributes, "BOOLEAN", "")
+	return asBool(val, err, defaultValue)
+}
+
+// GetStringValue : Get the current value of a STRING feature of TEXT format.
+// Returns defaultValue and ErrWrongDataType or ErrWrongDataFormat when the feature is of another data type or format.
+func (f *Feature) GetStringValue(entityID string, entityAttributes map[string]interface{}, defaultValue string) (string, error) {
+	val, err := f.evaluateAs(entityID, entityAttributes, "STRING", "TEXT")
+	return asString(val, err, defaultValue)
+}
+
+// GetFloat64Value : Get the current value of a NUMERIC feature.
+// Returns defaultValue and ErrWrongDataType when the feature is of another data type.
+func (f *Feature) GetFloat64Value(entityID string, entityAttributes map[string]interface{}, defaultValue float64) (float64, error) {
+	val, err := f.evaluateAs(entityID, entityAttributes, "NUMERIC", "")
+	return asFloat64(val, err, defaultValue)
+}
+
+// GetIntValue : Get the current value of a NUMERIC feature holding a whole number.
+// Returns defaultValue and ErrWrongDataType when the feature is of another data type, or ErrEvaluationFailed when the value is not a whole number.
+func (f *Feature) GetIntValue(entityID string, entityAttributes map[string]interface{}, defaultValue int) (int, error) {
+	val, err := f.evaluateAs(entityID, entityAttributes, "NUMERIC", "")
+	return asInt(val, err, defaultValue)
+}
+
+// GetJSONValue : Decode the current value of a STRING feature of JSON format into the value pointed to by into.
+// into is left unchanged, so it can hold the default value, when ErrWrongDataType, ErrWrongDataFormat or ErrEvaluationFailed is returned.
+func (f *Feature) GetJSONValue(entityID string, entityAttributes map[string]interface{}, into interface{}) error {
+	val, err := f.evaluateAs(entityID, entityAttributes, "STRING", "JSON")
+	return decodeJSON(val, err, into)
+}
+
+// GetYAMLValue : Decode the current value of a STRING feature of YAML format into the value pointed to by into.
+// into is left unchanged, so it can hold the default value, when ErrWrongDataType, ErrWrongDataFormat or ErrEvaluationFailed is returned.
+func (f *Feature) GetYAMLValue(entityID string, entityAttributes map[string]interface{}, into interface{}) error {
+	val, err := f.evaluateAs(entityID, entityAttributes, "STRING", "YAML")
+	return decodeYAML(val, err, into)
+}
+
+// evaluateAs : Evaluate the feature after checking it is of the expected data type and format. The value is not type casted.
+func (f *Feature) evaluateAs(entityID string, entityAttributes map[string]interface{}, dataType string, dataFormat string) (interface{}, error) {
+	if err := checkDataType(f.GetFeatureDataType(), f.GetFeatureDataFormat(), dataType, dataFormat); err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	if len(entityID) <= 0 {
+		log.Error(messages.SetEntityObjectIDError)
+		return nil, fmt.Errorf("%w: %s", ErrEvaluationFailed, messages.SetEntityObjectIDError)
+	}
+	if !f.isFeatureValid() {
+		return nil, ErrEvaluationFailed
+	}
+	return f.featureEvaluation(entityID, entityAttributes), nil
+}
+
+// EvaluateDetailed : Evaluate the feature like GetCurrentValue and return the value together with the reason for it,
+// the segment and segment rule that matched and the trace of the segments evaluated.
+func (f *Feature) EvaluateDetailed(entityID string, entityAttributes map[string]interface{}) EvaluationDetails {
+	log.Debug(messages.RetrievingFeature)
+	if len(entityID) <= 0 {
+		log.Error(messages.SetEntityObjectIDError)
+		return EvaluationDetails{Reason: EvaluationReasonInvalid}
+	}
+	if !f.isFeatureValid() {
+		return EvaluationDetails{Reason: EvaluationReasonInvalid}
+	}
+	details := f.evaluate(entityID, entityAttributes, true)
+	details.Value = getTypeCastedValue(details.Value, f.GetFeatureDataType(), f.GetFeatureDataFormat())
+	if details.Value == nil && details.Reason != EvaluationReasonInvalid {
+		details.Reason = EvaluationReasonTypeError
+	}
+	return details
+}
+
 func (f *Feature) featureEvaluation(entityID string, entityAttributes map[string]interface{}) interface{} {
+	return f.evaluate(entityID, entityAttributes, false).Value
+}
+
+func (f *Feature) evaluate(entityID string, entityAttributes map[string]interface{}, trace bool) (details EvaluationDetails) {
 
 	var evaluatedSegmentID string = constants.DefaultSegmentID
 	defer func() {
-		utils.GetMeteringInstance().RecordEvaluation(f.GetFeatureID(), "", entityID, evaluatedSegmentID)
+		getMetering(f.cache).RecordEvaluation(f.GetFeatureID(), "", entityID, evaluatedSegmentID)
 	}()
-
-	if f.IsEnabled() {
-		log.Debug(messages.EvaluatingFeature)
-		defer utils.GracefullyHandleError()
-
-		if len(entityAttributes) < 0 {
-			log.Debug(f.GetEnabledValue())
-			return f.GetEnabledValue()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Debug(r)
+			details = EvaluationDetails{Reason: EvaluationReasonInvalid}
 		}
+	}()
 
-		if len(f.GetSegmentRules()) > 0 {
-
-			var rulesMap map[int]SegmentRule
-			rulesMap = f.parseRules(f.GetSegmentRules())
-
-			// sort the map elements as per ascending order of keys
-
-			var keys []int
-			for k := range rulesMap {
-				keys = append(keys, k)
-			}
-			sort.Ints(keys)
-
-			// after sorting , pick up each map element as per keys order
-			for _, k := range keys {
-				segmentRule := rulesMap[k]
-				for _, rule := range segmentRule.GetRules() {
-					for _, segmentKey := range rule.Segments {
-						if f.evaluateSegment(string(segmentKey), entityAttributes) {
-							evaluatedSegmentID = segmentKey
-							if segmentRule.GetValue() == "$default" {
-								log.Debug(messages.FeatureValue)
-								log.Debug(f.GetEnabledValue())
-								return f.GetEnabledValue()
-							}
-							log.Debug(messages.FeatureValue)
-							log.Debug(segmentRule.GetValue())
-							return segmentRule.GetValue()
-						}
-					}
-				}
-			}
-		} else {
-			return f.GetEnabledValue()
-		}
-		return f.GetEnabledValue()
+	if !f.IsEnabled() {
+		return EvaluationDetails{Value: f.GetDisabledValue(), Reason: EvaluationReasonDisabled}
 	}
-	return f.GetDisabledValue()
+	log.Debug(messages.EvaluatingFeature)
+	if len(f.GetSegmentRules()) == 0 {
+		return EvaluationDetails{Value: f.GetEnabledValue(), Reason: EvaluationReasonDefaultNoRules}
+	}
+	segmentRule, matched := matchSegmentRules(f.parseRules(f.GetSegmentRules()), getSegmentMap(f.cache), entityAttributes, &details, trace)
+	if !matched {
+		details.Value = f.GetEnabledValue()
+		details.Reason = EvaluationReasonNoSegmentMatched
+		return details
+	}
+	evaluatedSegmentID = details.SegmentID
+	log.Debug(messages.FeatureValue)
+	if segmentRule.GetValue() == "$default" {
+		details.Value = f.GetEnabledValue()
+		details.Reason = EvaluationReasonSegmentMatchDefaultValue
+	} else {
+		details.Value = segmentRule.GetValue()
+		details.Reason = EvaluationReasonSegmentMatch
+	}
+	log.Debug(details.Value)
+	return details
 }
 func (f *Feature) parseRules(segmentRules []SegmentRule) map[int]SegmentRule {
 	log.Debug(messages.ParsingFeatureRules)
@@ -173,11 +246,3 @@ func (f *Feature) parseRules(segmentRules []SegmentRule) map[int]SegmentRule {
 	log.Debug(rulesMap)
 	return rulesMap
 }
-func (f *Feature) evaluateSegment(segmentKey string, entityAttributes map[string]interface{}) bool {
-	log.Debug(messages.EvaluatingSegments)
-	segment, ok := GetCacheInstance().SegmentMap[segmentKey]
-	if ok {
-		return segment.EvaluateRule(entityAttributes)
-	}
-	return false
-}
@@ -21,7 +21,8 @@ import (
 	messages "github.com/IBM/appconfiguration-go-sdk/lib/internal/messages"
 	utils "github.com/IBM/appconfiguration-go-sdk/lib/internal/utils"
 
-	"sort"
+	"fmt"
+	"reflect"
 
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
 )
@@ -34,6 +35,13 @@ type Property struct {
 	Format       string        `json:"format"`
 	Value        interface{}   `json:"value"`
 	SegmentRules []SegmentRule `json:"segment_rules"`
+	cache        *Cache
+}
+
+// Equal : Check whether the property has the same configuration as other, regardless of the cache they belong to
+func (p Property) Equal(other Property) bool {
+	p.cache, other.cache = nil, nil
+	return reflect.DeepEqual(p, other)
 }
 
 // GetPropertyName : Get Property Name
@@ -93,57 +101,121 @@ func (p *Property) isPropertyValid() bool {
 	return !(p.Name == "" || p.PropertyID == "" || p.DataType == "" || p.Value == nil)
 }
 
-func (p *Property) propertyEvaluation(entityID string, entityAttributes map[string]interface{}) interface{} {
+// GetBoolValue : Get the current value of a BOOLEAN property.
+// Returns defaultValue and ErrWrongDataType when the property is of another data type, or ErrEvaluationFailed when it cannot be evaluated.
+func (p *Property) GetBoolValue(entityID string, entityAttributes map[string]interface{}, defaultValue bool) (bool, error) {
+	val, err := p.evaluateAs(entityID, entityAttributes, "BOOLEAN", "")
+	return asBool(val, err, defaultValue)
+}
 
-	var evaluatedSegmentID string = constants.DefaultSegmentID
-	defer func() {
-		utils.GetMeteringInstance().RecordEvaluation("", p.GetPropertyID(), entityID, evaluatedSegmentID)
-	}()
+// GetStringValue : Get the current value of a STRING property of TEXT format.
+// Returns defaultValue and ErrWrongDataType or ErrWrongDataFormat when the property is of another data type or format.
+func (p *Property) GetStringValue(entityID string, entityAttributes map[string]interface{}, defaultValue string) (string, error) {
+	val, err := p.evaluateAs(entityID, entityAttributes, "STRING", "TEXT")
+	return asString(val, err, defaultValue)
+}
 
-	log.Debug(messages.EvaluatingProperty)
-	defer utils.GracefullyHandleError()
+// GetFloat64Value : Get the current value of a NUMERIC property.
+// Returns defaultValue and ErrWrongDataType when the property is of another data type.
+func (p *Property) GetFloat64Value(entityID string, entityAttributes map[string]interface{}, defaultValue float64) (float64, error) {
+	val, err := p.evaluateAs(entityID, entityAttributes, "NUMERIC", "")
+	return asFloat64(val, err, defaultValue)
+}
 
-	if len(entityAttributes) < 0 {
-		log.Debug(p.GetValue())
-		return p.GetValue()
+// GetIntValue : Get the current value of a NUMERIC property holding a whole number.
+// Returns defaultValue and ErrWrongDataType when the property is of another data type, or ErrEvaluationFailed when the value is not a whole number.
+func (p *Property) GetIntValue(entityID string, entityAttributes map[string]interface{}, defaultValue int) (int, error) {
+	val, err := p.evaluateAs(entityID, entityAttributes, "NUMERIC", "")
+	return asInt(val, err, defaultValue)
+}
+
+// GetJSONValue : Decode the current value of a STRING property of JSON format into the value pointed to by into.
+// into is left unchanged, so it can hold the default value, when ErrWrongDataType, ErrWrongDataFormat or ErrEvaluationFailed is returned.
+func (p *Property) GetJSONValue(entityID string, entityAttributes map[string]interface{}, into interface{}) error {
+	val, err := p.evaluateAs(entityID, entityAttributes, "STRING", "JSON")
+	return decodeJSON(val, err, into)
+}
+
+// GetYAMLValue : Decode the current value of a STRING property of YAML format into the value pointed to by into.
+// into is left unchanged, so it can hold the default value, when ErrWrongDataType, ErrWrongDataFormat or ErrEvaluationFailed is returned.
+func (p *Property) GetYAMLValue(entityID string, entityAttributes map[string]interface{}, into interface{}) error {
+	val, err := p.evaluateAs(entityID, entityAttributes, "STRING", "YAML")
+	return decodeYAML(val, err, into)
+}
+
+// evaluateAs : Evaluate the property after checking it is of the expected data type and format. The value is not type casted.
+func (p *Property) evaluateAs(entityID string, entityAttributes map[string]interface{}, dataType string, dataFormat string) (interface{}, error) {
+	if err := checkDataType(p.GetPropertyDataType(), p.GetPropertyDataFormat(), dataType, dataFormat); err != nil {
+		log.Error(err)
+		return nil, err
 	}
+	if len(entityID) <= 0 {
+		log.Error(messages.SetEntityObjectIDError)
+		return nil, fmt.Errorf("%w: %s", ErrEvaluationFailed, messages.SetEntityObjectIDError)
+	}
+	if !p.isPropertyValid() {
+		return nil, ErrEvaluationFailed
+	}
+	return p.propertyEvaluation(entityID, entityAttributes), nil
+}
 
-	if len(p.GetSegmentRules()) > 0 {
+// EvaluateDetailed : Evaluate the property like GetCurrentValue and return the value together with the reason for it,
+// the segment and segment rule that matched and the trace of the segments evaluated.
+func (p *Property) EvaluateDetailed(entityID string, entityAttributes map[string]interface{}) EvaluationDetails {
+	log.Debug(messages.RetrievingProperty)
+	if len(entityID) <= 0 {
+		log.Error(messages.SetEntityObjectIDError)
+		return EvaluationDetails{Reason: EvaluationReasonInvalid}
+	}
+	if !p.isPropertyValid() {
+		return EvaluationDetails{Reason: EvaluationReasonInvalid}
+	}
+	details := p.evaluate(entityID, entityAttributes, true)
+	details.Value = getTypeCastedValue(details.Value, p.GetPropertyDataType(), p.GetPropertyDataFormat())
+	if details.Value == nil && details.Reason != EvaluationReasonInvalid {
+		details.Reason = EvaluationReasonTypeError
+	}
+	return details
+}
 
-		var rulesMap map[int]SegmentRule
-		rulesMap = p.parseRules(p.GetSegmentRules())
+func (p *Property) propertyEvaluation(entityID string, entityAttributes map[string]interface{}) interface{} {
+	return p.evaluate(entityID, entityAttributes, false).Value
+}
 
-		// sort the map elements as per ascending order of keys
+func (p *Property) evaluate(entityID string, entityAttributes map[string]interface{}, trace bool) (details EvaluationDetails) {
 
-		var keys []int
-		for k := range rulesMap {
-			keys = append(keys, k)
-		}
-		sort.Ints(keys)
-
-		// after sorting , pick up each map element as per keys order
-		for _, k := range keys {
-			segmentRule := rulesMap[k]
-			for _, rule := range segmentRule.GetRules() {
-				for _, segmentKey := range rule.Segments {
-					if p.evaluateSegment(string(segmentKey), entityAttributes) {
-						evaluatedSegmentID = segmentKey
-						if segmentRule.GetValue() == "$default" {
-							log.Debug(messages.PropertyValue)
-							log.Debug(p.GetValue())
-							return p.GetValue()
-						}
-						log.Debug(messages.PropertyValue)
-						log.Debug(segmentRule.GetValue())
-						return segmentRule.GetValue()
-					}
-				}
-			}
+	var evaluatedSegmentID string = constants.DefaultSegmentID
+	defer func() {
+		getMetering(p.cache).RecordEvaluation("", p.GetPropertyID(), entityID, evaluatedSegmentID)
+	}()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Debug(r)
+			details = EvaluationDetails{Reason: EvaluationReasonInvalid}
 		}
+	}()
+
+	log.Debug(messages.EvaluatingProperty)
+	if len(p.GetSegmentRules()) == 0 {
+		return EvaluationDetails{Value: p.GetValue(), Reason: EvaluationReasonDefaultNoRules}
+	}
+	segmentRule, matched := matchSegmentRules(p.parseRules(p.GetSegmentRules()), getSegmentMap(p.cache), entityAttributes, &details, trace)
+	if !matched {
+		details.Value = p.GetValue()
+		details.Reason = EvaluationReasonNoSegmentMatched
+		return details
+	}
+	evaluatedSegmentID = details.SegmentID
+	log.Debug(messages.PropertyValue)
+	if segmentRule.GetValue() == "$default" {
+		details.Value = p.GetValue()
+		details.Reason = EvaluationReasonSegmentMatchDefaultValue
 	} else {
-		return p.GetValue()
+		details.Value = segmentRule.GetValue()
+		details.Reason = EvaluationReasonSegmentMatch
 	}
-	return p.GetValue()
+	log.Debug(details.Value)
+	return details
 }
 func (p *Property) parseRules(segmentRules []SegmentRule) map[int]SegmentRule {
 	log.Debug(messages.ParsingPropertyRules)
@@ -156,11 +228,3 @@ func (p *Property) parseRules(segmentRules []SegmentRule) map[int]SegmentRule {
 	log.Debug(rulesMap)
 	return rulesMap
 }
-func (p *Property) evaluateSegment(segmentKey string, entityAttributes map[string]interface{}) bool {
-	log.Debug(messages.EvaluatingSegments)
-	segment, ok := GetCacheInstance().SegmentMap[segmentKey]
-	if ok {
-		return segment.EvaluateRule(entityAttributes)
-	}
-	return false
-}
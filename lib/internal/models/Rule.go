@@ -194,10 +194,37 @@ func (r *Rule) EvaluateRule(entityAttributes map[string]interface{}) bool {
 	if !ok {
 		return false
 	}
-	for _, val := range r.GetValues() {
-		if r.operatorCheck(key, val) {
-			result = true
+	// a list attribute satisfies the rule when any of its elements does
+	var keys []interface{}
+	switch list := key.(type) {
+	case []string:
+		for _, k := range list {
+			keys = append(keys, k)
+		}
+	case []interface{}:
+		keys = list
+	default:
+		keys = []interface{}{key}
+	}
+	for _, k := range keys {
+		for _, val := range r.GetValues() {
+			if r.operatorCheck(k, val) {
+				result = true
+			}
 		}
 	}
 	return result
 }
+
+// evaluateRuleWithTrace : Evaluate the rule and record the attribute value it was evaluated against
+func (r *Rule) evaluateRuleWithTrace(entityAttributes map[string]interface{}) RuleEvaluation {
+	key, ok := entityAttributes[r.GetAttributeName()]
+	return RuleEvaluation{
+		AttributeName:  r.GetAttributeName(),
+		Operator:       r.GetOperator(),
+		Values:         r.GetValues(),
+		AttributeValue: key,
+		AttributeFound: ok,
+		Result:         r.EvaluateRule(entityAttributes),
+	}
+}
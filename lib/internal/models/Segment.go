@@ -17,6 +17,8 @@
 package models
 
 import (
+	"reflect"
+
 	messages "github.com/IBM/appconfiguration-go-sdk/lib/internal/messages"
 	utils "github.com/IBM/appconfiguration-go-sdk/lib/internal/utils"
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
@@ -29,6 +31,11 @@ type Segment struct {
 	Rules     []Rule `json:"rules"`
 }
 
+// Equal : Check whether the segment has the same configuration as other
+func (s Segment) Equal(other Segment) bool {
+	return reflect.DeepEqual(s, other)
+}
+
 // GetName : Get Name
 func (s *Segment) GetName() string {
 	return s.Name
@@ -55,3 +62,18 @@ func (s *Segment) EvaluateRule(entityAttributes map[string]interface{}) bool {
 	}
 	return true
 }
+
+// evaluateRuleWithTrace : Evaluate every rule of the segment, unlike EvaluateRule which stops at the first failing rule, and record their results
+func (s *Segment) evaluateRuleWithTrace(entityAttributes map[string]interface{}) (bool, []RuleEvaluation) {
+	log.Debug(messages.EvalSegmentRule)
+	var result = true
+	var ruleEvaluations []RuleEvaluation
+	for _, rule := range s.GetRules() {
+		ruleEvaluation := rule.evaluateRuleWithTrace(entityAttributes)
+		if !ruleEvaluation.Result {
+			result = false
+		}
+		ruleEvaluations = append(ruleEvaluations, ruleEvaluation)
+	}
+	return result, ruleEvaluations
+}
@@ -17,6 +17,7 @@
 package models
 
 import (
+	"errors"
 	"os"
 	"reflect"
 	"testing"
@@ -259,6 +260,165 @@ func TestProperty(t *testing.T) {
 	}
 }
 
+func TestFeatureTypedValues(t *testing.T) {
+	entityMap := map[string]interface{}{"attribute_name": "first"}
+	f := Feature{Name: "f", FeatureID: "f", DataType: "BOOLEAN", EnabledValue: true, DisabledValue: false, Enabled: true}
+	boolVal, err := f.GetBoolValue("entityID123", entityMap, false)
+	assert.Nil(t, err)
+	assert.Equal(t, true, boolVal)
+
+	// wrong data type returns the default value
+	stringVal, err := f.GetStringValue("entityID123", entityMap, "default")
+	assert.True(t, errors.Is(err, ErrWrongDataType))
+	assert.Equal(t, "default", stringVal)
+
+	// empty entity id returns the default value
+	boolVal, err = f.GetBoolValue("", entityMap, false)
+	assert.True(t, errors.Is(err, ErrEvaluationFailed))
+	assert.Equal(t, false, boolVal)
+
+	f = Feature{Name: "f", FeatureID: "f", DataType: "NUMERIC", EnabledValue: float64(25), DisabledValue: float64(2.5), Enabled: true}
+	floatVal, err := f.GetFloat64Value("entityID123", entityMap, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(25), floatVal)
+	intVal, err := f.GetIntValue("entityID123", entityMap, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 25, intVal)
+	f.Enabled = false
+	intVal, err = f.GetIntValue("entityID123", entityMap, 1)
+	assert.True(t, errors.Is(err, ErrEvaluationFailed))
+	assert.Equal(t, 1, intVal)
+
+	f = Feature{Name: "f", FeatureID: "f", DataType: "STRING", Format: "JSON", EnabledValue: map[string]interface{}{"name": "Firefox", "version": float64(90)}, DisabledValue: map[string]interface{}{}, Enabled: true}
+	var browser struct {
+		Name    string `json:"name"`
+		Version int    `json:"version"`
+	}
+	err = f.GetJSONValue("entityID123", entityMap, &browser)
+	assert.Nil(t, err)
+	assert.Equal(t, "Firefox", browser.Name)
+	assert.Equal(t, 90, browser.Version)
+	var people map[string][]string
+	err = f.GetYAMLValue("entityID123", entityMap, &people)
+	assert.True(t, errors.Is(err, ErrWrongDataFormat))
+	assert.Nil(t, people)
+
+	f = Feature{Name: "f", FeatureID: "f", DataType: "STRING", Format: "YAML", EnabledValue: "men:\n  - John Smith\n  - Bill Jones", DisabledValue: "key: value", Enabled: true}
+	err = f.GetYAMLValue("entityID123", entityMap, &people)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"John Smith", "Bill Jones"}, people["men"])
+
+	f = Feature{Name: "f", FeatureID: "f", DataType: "STRING", EnabledValue: "on", DisabledValue: "off", Enabled: true}
+	stringVal, err = f.GetStringValue("entityID123", entityMap, "default")
+	assert.Nil(t, err)
+	assert.Equal(t, "on", stringVal)
+}
+
+func TestPropertyTypedValues(t *testing.T) {
+	entityMap := map[string]interface{}{"attribute_name": "first"}
+	p := Property{Name: "p", PropertyID: "p", DataType: "NUMERIC", Value: float64(2.5)}
+	floatVal, err := p.GetFloat64Value("entityID123", entityMap, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 2.5, floatVal)
+
+	// not a whole number
+	intVal, err := p.GetIntValue("entityID123", entityMap, 3)
+	assert.True(t, errors.Is(err, ErrEvaluationFailed))
+	assert.Equal(t, 3, intVal)
+
+	boolVal, err := p.GetBoolValue("entityID123", entityMap, true)
+	assert.True(t, errors.Is(err, ErrWrongDataType))
+	assert.Equal(t, true, boolVal)
+
+	p = Property{Name: "p", PropertyID: "p", DataType: "STRING", Format: "YAML", Value: "key: value"}
+	var result map[string]string
+	err = p.GetYAMLValue("entityID123", entityMap, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, "value", result["key"])
+	err = p.GetJSONValue("entityID123", entityMap, &result)
+	assert.True(t, errors.Is(err, ErrWrongDataFormat))
+}
+
+func TestEvaluateDetailed(t *testing.T) {
+	segmentMap := map[string]Segment{
+		"beta":  {Name: "Beta users", SegmentID: "beta", Rules: []Rule{{AttributeName: "email", Operator: "endsWith", Values: []interface{}{"@ibm.com"}}}},
+		"india": {Name: "India", SegmentID: "india", Rules: []Rule{{AttributeName: "country", Operator: "is", Values: []interface{}{"IN"}}}},
+	}
+	segmentRules := []SegmentRule{
+		{Order: 2, Value: "$default", Rules: []RuleElem{{Segments: []string{"india"}}}},
+		{Order: 1, Value: "beta value", Rules: []RuleElem{{Segments: []string{"unknown", "beta"}}}},
+	}
+	featureMap := map[string]Feature{
+		"f": {Name: "f", FeatureID: "f", DataType: "STRING", EnabledValue: "on", DisabledValue: "off", Enabled: true, SegmentRules: segmentRules},
+	}
+	propertyMap := map[string]Property{
+		"p": {Name: "p", PropertyID: "p", DataType: "STRING", Value: "value", SegmentRules: segmentRules},
+	}
+	cache := NewCache(featureMap, propertyMap, segmentMap, nil)
+	f := cache.FeatureMap["f"]
+	p := cache.PropertyMap["p"]
+
+	details := f.EvaluateDetailed("entityID123", map[string]interface{}{"email": "alice@ibm.com"})
+	assert.Equal(t, "beta value", details.Value)
+	assert.Equal(t, EvaluationReasonSegmentMatch, details.Reason)
+	assert.Equal(t, "beta", details.SegmentID)
+	assert.Equal(t, "Beta users", details.SegmentName)
+	assert.Equal(t, 1, details.RuleOrder)
+	assert.Len(t, details.Trace, 2)
+	assert.False(t, details.Trace[0].Found)
+	assert.True(t, details.Trace[1].Matched)
+	assert.Equal(t, []RuleEvaluation{{AttributeName: "email", Operator: "endsWith", Values: []interface{}{"@ibm.com"}, AttributeValue: "alice@ibm.com", AttributeFound: true, Result: true}}, details.Trace[1].Rules)
+
+	details = f.EvaluateDetailed("entityID123", map[string]interface{}{"country": "IN"})
+	assert.Equal(t, "on", details.Value)
+	assert.Equal(t, EvaluationReasonSegmentMatchDefaultValue, details.Reason)
+	assert.Equal(t, "india", details.SegmentID)
+	assert.Equal(t, 2, details.RuleOrder)
+	assert.Len(t, details.Trace, 3)
+
+	details = f.EvaluateDetailed("entityID123", map[string]interface{}{"country": "US"})
+	assert.Equal(t, "on", details.Value)
+	assert.Equal(t, EvaluationReasonNoSegmentMatched, details.Reason)
+	assert.Equal(t, "", details.SegmentID)
+	assert.Len(t, details.Trace, 3)
+	assert.False(t, details.Trace[1].Rules[0].AttributeFound)
+
+	details = p.EvaluateDetailed("entityID123", map[string]interface{}{"email": "alice@ibm.com"})
+	assert.Equal(t, "beta value", details.Value)
+	assert.Equal(t, EvaluationReasonSegmentMatch, details.Reason)
+	details = p.EvaluateDetailed("entityID123", nil)
+	assert.Equal(t, "value", details.Value)
+	assert.Equal(t, EvaluationReasonNoSegmentMatched, details.Reason)
+
+	f.Enabled = false
+	details = f.EvaluateDetailed("entityID123", map[string]interface{}{"email": "alice@ibm.com"})
+	assert.Equal(t, "off", details.Value)
+	assert.Equal(t, EvaluationReasonDisabled, details.Reason)
+	assert.Nil(t, details.Trace)
+	f.Enabled = true
+
+	f.SegmentRules = nil
+	details = f.EvaluateDetailed("entityID123", nil)
+	assert.Equal(t, "on", details.Value)
+	assert.Equal(t, EvaluationReasonDefaultNoRules, details.Reason)
+	p.SegmentRules = nil
+	details = p.EvaluateDetailed("entityID123", nil)
+	assert.Equal(t, "value", details.Value)
+	assert.Equal(t, EvaluationReasonDefaultNoRules, details.Reason)
+
+	details = f.EvaluateDetailed("", nil)
+	assert.Nil(t, details.Value)
+	assert.Equal(t, EvaluationReasonInvalid, details.Reason)
+	p.PropertyID = ""
+	details = p.EvaluateDetailed("entityID123", nil)
+	assert.Equal(t, EvaluationReasonInvalid, details.Reason)
+
+	f.DataType = "NUMERIC"
+	details = f.EvaluateDetailed("entityID123", nil)
+	assert.Nil(t, details.Value)
+	assert.Equal(t, EvaluationReasonTypeError, details.Reason)
+}
+
 func TestSegment(t *testing.T) {
 	if segment.GetName() != "segmentName" {
 		t.Error("Expected TestSegmentGetName test case to pass")
@@ -309,6 +469,12 @@ func TestRule(t *testing.T) {
 		t.Error("Expected TestRuleEvaluateRule test case to pass")
 	}
 
+	listRule := Rule{Operator: "is", AttributeName: "groups", Values: []interface{}{"beta"}}
+	assert.True(t, listRule.EvaluateRule(map[string]interface{}{"groups": []string{"admins", "beta"}}))
+	assert.True(t, listRule.EvaluateRule(map[string]interface{}{"groups": []interface{}{"beta"}}))
+	assert.False(t, listRule.EvaluateRule(map[string]interface{}{"groups": []string{"admins"}}))
+	assert.False(t, listRule.EvaluateRule(map[string]interface{}{"groups": []string{}}))
+
 	//
 	if isNumber(1) != true {
 		t.Error("Expected TestIsNumber test case to pass when input provided is a number.")
@@ -18,6 +18,8 @@ package utils
 
 import (
 	"encoding/json"
+	"reflect"
+	"sync"
 
 	"github.com/IBM/go-sdk-core/v5/core"
 )
@@ -25,6 +27,9 @@ import (
 // APIManager : wrapper struct over core base service.
 type APIManager struct {
 	baseService *core.BaseService
+	urlBuilder  *URLBuilder
+	transport   TransportOptions
+	mu          sync.Mutex
 }
 
 var apiManagerInstance *APIManager
@@ -37,18 +42,80 @@ func GetAPIManagerInstance() *APIManager {
 	return apiManagerInstance
 }
 
+// NewAPIManager : returns a new APIManager which sends its requests to the service url and with the authenticator of the given url builder.
+func NewAPIManager(urlBuilder *URLBuilder) *APIManager {
+	return &APIManager{
+		urlBuilder: urlBuilder,
+	}
+}
+
+// SetTransportOptions : sets the HTTP client, request timeout and additional headers of the requests sent by the APIManager.
+func (ap *APIManager) SetTransportOptions(transport TransportOptions) {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	ap.transport = transport
+	// the base service is created again with the new client by the next request
+	ap.baseService = nil
+}
+
+// GetTransportOptions : returns the transport options of the APIManager.
+func (ap *APIManager) GetTransportOptions() TransportOptions {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	return ap.transport
+}
+
+func (ap *APIManager) getURLBuilder() *URLBuilder {
+	if ap.urlBuilder != nil {
+		return ap.urlBuilder
+	}
+	return urlBuilderInstance
+}
+
+// getBaseService : returns the long-lived base service, which is only created again when the service url or the
+// authenticator of the url builder, or the transport options have changed since it was created.
+func (ap *APIManager) getBaseService() (*core.BaseService, error) {
+	urlBuilder := ap.getURLBuilder()
+	serviceURL := urlBuilder.GetBaseServiceURL()
+	authenticator := urlBuilder.GetAuthenticator()
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	if ap.baseService != nil && ap.baseService.GetServiceURL() == serviceURL && sameAuthenticator(ap.baseService.Options.Authenticator, authenticator) {
+		return ap.baseService, nil
+	}
+	baseService, err := core.NewBaseService(&core.ServiceOptions{
+		URL:           serviceURL,
+		Authenticator: authenticator,
+	})
+	if err != nil {
+		return nil, err
+	}
+	baseService.SetHTTPClient(ap.transport.Client())
+	ap.baseService = baseService
+	return baseService, nil
+}
+
+// sameAuthenticator : reports whether both authenticators are the same one. Authenticators of types which are not comparable
+// are never reported as the same.
+func sameAuthenticator(a, b core.Authenticator) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return reflect.TypeOf(a) == reflect.TypeOf(b) && reflect.TypeOf(a).Comparable() && a == b
+}
+
 // Request : wrapper over core base service request method.
 func (ap *APIManager) Request(builder *core.RequestBuilder) *core.DetailedResponse {
-	serviceOptions := &core.ServiceOptions{
-		URL:           urlBuilderInstance.GetBaseServiceURL(),
-		Authenticator: urlBuilderInstance.GetAuthenticator(),
+	baseService, err := ap.getBaseService()
+	if err != nil {
+		return nil
 	}
-	ap.baseService, _ = core.NewBaseService(serviceOptions)
 	request, err := builder.Build()
 	if err != nil {
 		return nil
 	}
+	ap.GetTransportOptions().AddHeaders(request.Header)
 	var rawResponse map[string]json.RawMessage
-	response, _ := ap.baseService.Request(request, &rawResponse)
+	response, _ := baseService.Request(request, &rawResponse)
 	return response
 }
@@ -18,14 +18,22 @@ package utils
 
 import (
 	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/constants"
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/messages"
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/utils/log"
-	"io/ioutil"
-	"path"
 )
 
-// StoreFiles : Store Files
+// storeFilesMu : serialises the writers of the persistent cache files
+var storeFilesMu sync.Mutex
+
+// StoreFiles : Store Files. The content is written to a temporary file in the same directory, synced to the disk and
+// renamed over the configuration file, so that a crash or a concurrent write never leaves a truncated file behind.
 func StoreFiles(content, filePath string) {
 	log.Debug(messages.StoreFile)
 
@@ -34,20 +42,69 @@ func StoreFiles(content, filePath string) {
 		log.Error(messages.EncodeJSONErr, err)
 		return
 	}
-	err = ioutil.WriteFile(path.Join(filePath, constants.ConfigurationFile), file, 0644)
+	storeFilesMu.Lock()
+	defer storeFilesMu.Unlock()
+	err = writeFileAtomically(path.Join(filePath, constants.ConfigurationFile), file, 0644)
 	if err != nil {
 		log.Error(messages.WriteFileErr, err)
 		return
 	}
 }
 
-// ReadFiles reads file from the file path
+// writeFileAtomically : write data to a temporary file next to fileName, sync it and rename it to fileName
+func writeFileAtomically(fileName string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(fileName)
+	tempFile, err := ioutil.TempFile(dir, "."+filepath.Base(fileName)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tempName := tempFile.Name()
+	_, err = tempFile.Write(data)
+	if err == nil {
+		err = tempFile.Sync()
+	}
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tempName, perm)
+	}
+	if err == nil {
+		err = os.Rename(tempName, fileName)
+	}
+	if err != nil {
+		os.Remove(tempName)
+		return err
+	}
+	// sync the directory too, so that the rename survives a crash. Not every platform supports it.
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// ReadFiles reads file from the file path. A file which cannot be read or is not valid JSON, such as one truncated by a
+// crash, is reported and read as an empty JSON object.
 func ReadFiles(filePath string) []byte {
+	file, err := ReadFile(filePath)
+	if err != nil {
+		return []byte(`{}`)
+	}
+	if !json.Valid(file) {
+		log.Error(messages.InvalidJSONFileErr, filePath)
+		return []byte(`{}`)
+	}
+	return file
+}
+
+// ReadFile reads file from the file path and, unlike ReadFiles, returns the error when the file cannot be read
+func ReadFile(filePath string) ([]byte, error) {
 	log.Debug(messages.ReadFile)
 	file, err := ioutil.ReadFile(filePath)
 	if err != nil {
 		log.Error(messages.ReadFileErr, err)
-		return []byte(`{}`)
+		return nil, err
 	}
-	return file
+	return file, nil
 }
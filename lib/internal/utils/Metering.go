@@ -17,8 +17,11 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/IBM/appconfiguration-go-sdk/lib/internal/constants"
@@ -55,6 +58,15 @@ type Metering struct {
 	CollectionID         string
 	EnvironmentID        string
 	guid                 string
+	urlBuilder           *URLBuilder
+	apiManager           *APIManager
+	cronJob              *cron.Cron
+	started              bool
+	retryPolicy          *RetryPolicy
+	clock                Clock
+	metricsRecorder      atomic.Value
+	ctx                  context.Context
+	cancel               context.CancelFunc
 	mu                   sync.Mutex
 	meteringFeatureData  map[string]map[string]map[string]map[string]map[string]map[string]featureMetric //guid->EnvironmentID->CollectionID->featureId->entityId->segmentId
 	meteringPropertyData map[string]map[string]map[string]map[string]map[string]map[string]featureMetric //guid->EnvironmentID->CollectionID->propertyId->entityId->segmentId
@@ -69,21 +81,94 @@ var meteringInstance *Metering
 func GetMeteringInstance() *Metering {
 	log.Debug(messages.RetrieveMeteringInstance)
 	if meteringInstance == nil {
-		meteringInstance = &Metering{}
-		guidFeatureMap := make(map[string]map[string]map[string]map[string]map[string]map[string]featureMetric)
-		guidPropertyMap := make(map[string]map[string]map[string]map[string]map[string]map[string]featureMetric)
-		meteringInstance.meteringFeatureData = guidFeatureMap
-		meteringInstance.meteringPropertyData = guidPropertyMap
-		// start sending metering data in the background
-		log.Debug(messages.StartSendingMeteringData)
-		c := cron.New()
-		c.AddFunc("@every "+SendInterval, meteringInstance.sendMetering)
-		c.Start()
-
+		meteringInstance = NewMetering(nil, nil)
 	}
 	return meteringInstance
 }
 
+// NewMetering : returns a new Metering instance, not shared with the instance returned by GetMeteringInstance,
+// which sends the usage data to the service url of the given url builder using the given api manager.
+// When they are nil the package level url builder and api manager are used.
+// The data is sent in the background only once Start is called.
+func NewMetering(urlBuilder *URLBuilder, apiManager *APIManager) *Metering {
+	mt := &Metering{
+		urlBuilder: urlBuilder,
+		apiManager: apiManager,
+	}
+	mt.ctx, mt.cancel = context.WithCancel(context.Background())
+	guidFeatureMap := make(map[string]map[string]map[string]map[string]map[string]map[string]featureMetric)
+	guidPropertyMap := make(map[string]map[string]map[string]map[string]map[string]map[string]featureMetric)
+	mt.meteringFeatureData = guidFeatureMap
+	mt.meteringPropertyData = guidPropertyMap
+	mt.cronJob = cron.New()
+	mt.cronJob.AddFunc("@every "+SendInterval, func() { mt.sendMetering(mt.ctx) })
+	return mt
+}
+
+// Start : Start sending the metering data in the background every SendInterval.
+// Calling it again, or after Close, does nothing.
+func (mt *Metering) Start() {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	if mt.started || mt.ctx.Err() != nil {
+		return
+	}
+	log.Debug(messages.StartSendingMeteringData)
+	mt.started = true
+	mt.cronJob.Start()
+}
+
+// Close : Stop sending the metering data in the background and send the data recorded so far to the server.
+// The retries of the requests still waiting are given up, and the data recorded so far is sent without retry.
+func (mt *Metering) Close() {
+	log.Debug(messages.StopSendingMeteringData)
+	mt.mu.Lock()
+	mt.cancel()
+	mt.cronJob.Stop()
+	mt.mu.Unlock()
+	// the requests in flight were aborted with mt.ctx, the data recorded since is sent once whatever the context
+	mt.sendMetering(context.Background())
+}
+
+// SetRetryPolicy : Set the retry policy of the requests sending the metering data, and the clock the retries wait on
+func (mt *Metering) SetRetryPolicy(policy RetryPolicy, clock Clock) {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	mt.retryPolicy = &policy
+	mt.clock = clock
+}
+
+func (mt *Metering) getRetryPolicy() (RetryPolicy, Clock) {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	if mt.retryPolicy == nil {
+		return DefaultRetryPolicy(), mt.clock
+	}
+	return *mt.retryPolicy, mt.clock
+}
+
+// SetMetricsRecorder : Set the recorder of the evaluations and of the batches sent, nil to discard them
+func (mt *Metering) SetMetricsRecorder(recorder MetricsRecorder) {
+	if recorder == nil {
+		recorder = NopMetricsRecorder{}
+	}
+	// atomic.Value only holds values of one concrete type, so the recorders are wrapped
+	mt.metricsRecorder.Store(metricsRecorderHolder{recorder})
+}
+
+// getMetricsRecorder : Get the recorder without taking mu, as it is read for every evaluation
+func (mt *Metering) getMetricsRecorder() MetricsRecorder {
+	holder, ok := mt.metricsRecorder.Load().(metricsRecorderHolder)
+	if !ok {
+		return NopMetricsRecorder{}
+	}
+	return holder.recorder
+}
+
+type metricsRecorderHolder struct {
+	recorder MetricsRecorder
+}
+
 // Init : Init
 func (mt *Metering) Init(guid string, environmentID string, collectionID string) {
 	mt.guid = guid
@@ -105,10 +190,10 @@ func (mt *Metering) addMetering(guid string, environmentID string, collectionID
 	meteringData := make(map[string]map[string]map[string]map[string]map[string]map[string]featureMetric)
 	var modifyKey string
 	if featureID != "" {
-		meteringData = meteringInstance.meteringFeatureData
+		meteringData = mt.meteringFeatureData
 		modifyKey = featureID
 	} else {
-		meteringData = meteringInstance.meteringPropertyData
+		meteringData = mt.meteringPropertyData
 		modifyKey = propertyID
 	}
 	if _, ok := meteringData[guid]; ok {
@@ -181,6 +266,15 @@ func (mt *Metering) addMetering(guid string, environmentID string, collectionID
 func (mt *Metering) RecordEvaluation(featureID string, propertyID string, entityID string, segmentID string) {
 	log.Debug(messages.RecordEval)
 	mt.addMetering(mt.guid, mt.EnvironmentID, mt.CollectionID, entityID, segmentID, featureID, propertyID)
+	metricsSegmentID := segmentID
+	if metricsSegmentID == constants.DefaultSegmentID {
+		metricsSegmentID = ""
+	}
+	if featureID != "" {
+		mt.getMetricsRecorder().RecordFeatureEvaluation(featureID, metricsSegmentID)
+	} else {
+		mt.getMetricsRecorder().RecordPropertyEvaluation(propertyID, metricsSegmentID)
+	}
 }
 func (mt *Metering) buildRequestBody(sendMeteringData map[string]map[string]map[string]map[string]map[string]map[string]featureMetric, guidMap map[string][]CollectionUsages, key string) {
 
@@ -223,7 +317,9 @@ func (mt *Metering) buildRequestBody(sendMeteringData map[string]map[string]map[
 		guidMap[guid] = append(guidMap[guid], collectionUsageArray...)
 	}
 }
-func (mt *Metering) sendMetering() {
+
+// sendMetering : Send the data recorded so far. The requests are aborted once ctx is done, and the retries once mt.ctx is done.
+func (mt *Metering) sendMetering(ctx context.Context) {
 	log.Debug(messages.TenMinExpiry)
 	defer GracefullyHandleError()
 	log.Debug(mt.meteringFeatureData)
@@ -259,15 +355,15 @@ func (mt *Metering) sendMetering() {
 		for _, collectionUsage := range val {
 			var count int = len(collectionUsage.Usages)
 			if count > constants.DefaultUsageLimit {
-				mt.sendSplitMetering(guid, collectionUsage, count)
+				mt.sendSplitMetering(ctx, guid, collectionUsage, count)
 			} else {
-				mt.sendToServer(guid, collectionUsage)
+				mt.sendToServer(ctx, guid, collectionUsage)
 			}
 		}
 	}
 
 }
-func (mt *Metering) sendSplitMetering(guid string, collectionUsages CollectionUsages, count int) {
+func (mt *Metering) sendSplitMetering(ctx context.Context, guid string, collectionUsages CollectionUsages, count int) {
 	var lim int = 0
 	subUsages := collectionUsages.Usages
 	for lim <= count {
@@ -283,33 +379,58 @@ func (mt *Metering) sendSplitMetering(guid string, collectionUsages CollectionUs
 		for i := lim; i < endIndex; i++ {
 			collectionUsageElem.Usages = append(collectionUsageElem.Usages, subUsages[i])
 		}
-		mt.sendToServer(guid, collectionUsageElem)
+		mt.sendToServer(ctx, guid, collectionUsageElem)
 		lim = lim + constants.DefaultUsageLimit
 	}
 }
-func (mt *Metering) sendToServer(guid string, collectionUsages CollectionUsages) {
+func (mt *Metering) sendToServer(ctx context.Context, guid string, collectionUsages CollectionUsages) {
 	log.Debug(messages.SendMeteringServer)
 	log.Debug(collectionUsages)
 	builder := core.NewRequestBuilder(core.POST)
 	pathParamsMap := map[string]string{
 		"guid": mt.guid,
 	}
-	_, err := builder.ResolveRequestURL(urlBuilderInstance.GetBaseServiceURL(), `/apprapp/events/v1/instances/{guid}/usage`, pathParamsMap)
+	_, err := builder.ResolveRequestURL(mt.getURLBuilder().GetBaseServiceURL(), `/apprapp/events/v1/instances/{guid}/usage`, pathParamsMap)
 	if err != nil {
+		mt.getMetricsRecorder().RecordMeteringBatchDropped()
 		return
 	}
 	builder.AddHeader("Accept", "application/json")
 	builder.AddHeader("Content-Type", "application/json")
 	builder.AddHeader("User-Agent", constants.UserAgent)
-	_, err = builder.SetBodyContentJSON(collectionUsages)
+	builder.WithContext(ctx)
+	policy, clock := mt.getRetryPolicy()
+	err = Retry(mt.ctx, policy, clock, func() error {
+		// the body is read by every attempt, so it is set again for each of them
+		if _, err := builder.SetBodyContentJSON(collectionUsages); err != nil {
+			return err
+		}
+		response := mt.getAPIManager().Request(builder)
+		if response != nil && response.StatusCode >= 200 && response.StatusCode <= 299 {
+			return nil
+		}
+		if response != nil {
+			return fmt.Errorf("status code %d", response.StatusCode)
+		}
+		return errors.New(messages.SendMeteringServerErr)
+	})
 	if err != nil {
-		return
-	}
-	response := GetAPIManagerInstance().Request(builder)
-	if response != nil && response.StatusCode >= 200 && response.StatusCode <= 299 {
-		log.Debug(messages.SendMeteringSuccess)
-	} else {
 		log.Error(messages.SendMeteringServerErr, err)
+		mt.getMetricsRecorder().RecordMeteringBatchDropped()
 		return
 	}
+	mt.getMetricsRecorder().RecordMeteringBatchSent()
+	log.Debug(messages.SendMeteringSuccess)
+}
+func (mt *Metering) getURLBuilder() *URLBuilder {
+	if mt.urlBuilder != nil {
+		return mt.urlBuilder
+	}
+	return urlBuilderInstance
+}
+func (mt *Metering) getAPIManager() *APIManager {
+	if mt.apiManager != nil {
+		return mt.apiManager
+	}
+	return GetAPIManagerInstance()
 }
@@ -0,0 +1,73 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"time"
+)
+
+// MetricsRecorder : Receives the measurements of the SDK. The methods are called from the goroutines evaluating the
+// features and properties and from the background goroutines of the SDK, so they must be safe for concurrent use and
+// return quickly.
+type MetricsRecorder interface {
+	// RecordFeatureEvaluation : A feature was evaluated. The segment id is the one of the segment rule that matched,
+	// empty when none did.
+	RecordFeatureEvaluation(featureID string, segmentID string)
+	// RecordPropertyEvaluation : A property was evaluated. See RecordFeatureEvaluation
+	RecordPropertyEvaluation(propertyID string, segmentID string)
+	// RecordFetch : An attempt to fetch the configurations from the server took duration and failed with err, when not nil
+	RecordFetch(duration time.Duration, err error)
+	// RecordWebSocketReconnect : The websocket got connected again after it was disconnected
+	RecordWebSocketReconnect()
+	// RecordCacheSize : The configurations in use for the collection and environment have the given number of features,
+	// properties and segments
+	RecordCacheSize(collectionID string, environmentID string, features int, properties int, segments int)
+	// RecordListenerLatency : The configuration update listeners took duration to handle an update
+	RecordListenerLatency(duration time.Duration)
+	// RecordMeteringBatchSent : A batch of usage data was sent to the server
+	RecordMeteringBatchSent()
+	// RecordMeteringBatchDropped : A batch of usage data could not be sent to the server and was dropped
+	RecordMeteringBatchDropped()
+}
+
+// NopMetricsRecorder : Metrics recorder discarding the measurements, used when none is set
+type NopMetricsRecorder struct{}
+
+// RecordFeatureEvaluation : Discard the measurement
+func (NopMetricsRecorder) RecordFeatureEvaluation(featureID string, segmentID string) {}
+
+// RecordPropertyEvaluation : Discard the measurement
+func (NopMetricsRecorder) RecordPropertyEvaluation(propertyID string, segmentID string) {}
+
+// RecordFetch : Discard the measurement
+func (NopMetricsRecorder) RecordFetch(duration time.Duration, err error) {}
+
+// RecordWebSocketReconnect : Discard the measurement
+func (NopMetricsRecorder) RecordWebSocketReconnect() {}
+
+// RecordCacheSize : Discard the measurement
+func (NopMetricsRecorder) RecordCacheSize(collectionID string, environmentID string, features int, properties int, segments int) {
+}
+
+// RecordListenerLatency : Discard the measurement
+func (NopMetricsRecorder) RecordListenerLatency(duration time.Duration) {}
+
+// RecordMeteringBatchSent : Discard the measurement
+func (NopMetricsRecorder) RecordMeteringBatchSent() {}
+
+// RecordMeteringBatchDropped : Discard the measurement
+func (NopMetricsRecorder) RecordMeteringBatchDropped() {}
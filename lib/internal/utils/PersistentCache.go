@@ -0,0 +1,102 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/constants"
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/messages"
+)
+
+// ErrPersistentCacheFormat : Returned by ParsePersistentCache when the data is not a persistent cache envelope
+var ErrPersistentCacheFormat = errors.New(messages.PersistentCacheFormatError)
+
+// ErrPersistentCacheChecksum : Returned by ParsePersistentCache when the checksum does not match the configurations
+var ErrPersistentCacheChecksum = errors.New(messages.PersistentCacheChecksumError)
+
+// PersistentCache : Envelope of the configurations stored in the persistent cache, recording the SDK version, the context
+// the configurations belong to, when and with which ETag they were fetched, and a checksum of the configurations.
+type PersistentCache struct {
+	SDKVersion     string          `json:"sdk_version"`
+	GUID           string          `json:"guid"`
+	CollectionID   string          `json:"collection_id"`
+	EnvironmentID  string          `json:"environment_id"`
+	FetchedAt      time.Time       `json:"fetched_at"`
+	ETag           string          `json:"etag,omitempty"`
+	Checksum       string          `json:"checksum"`
+	Configurations json.RawMessage `json:"configurations"`
+}
+
+// NewPersistentCache : returns the envelope of the configurations of the given context
+func NewPersistentCache(guid, collectionID, environmentID, etag string, fetchedAt time.Time, configurations []byte) PersistentCache {
+	return PersistentCache{
+		SDKVersion:     constants.SDKVersion,
+		GUID:           guid,
+		CollectionID:   collectionID,
+		EnvironmentID:  environmentID,
+		FetchedAt:      fetchedAt.UTC(),
+		ETag:           etag,
+		Checksum:       Checksum(configurations),
+		Configurations: configurations,
+	}
+}
+
+// ParsePersistentCache : parses the envelope stored in the persistent cache and verifies the checksum of its configurations.
+// Returns ErrPersistentCacheFormat or ErrPersistentCacheChecksum when the data cannot be used.
+func ParsePersistentCache(data []byte) (PersistentCache, error) {
+	var persistentCache PersistentCache
+	if err := json.Unmarshal(data, &persistentCache); err != nil || len(persistentCache.Configurations) == 0 {
+		return PersistentCache{}, ErrPersistentCacheFormat
+	}
+	if Checksum(persistentCache.Configurations) != persistentCache.Checksum {
+		return PersistentCache{}, ErrPersistentCacheChecksum
+	}
+	return persistentCache, nil
+}
+
+// Matches : reports whether the configurations belong to the given context
+func (pc PersistentCache) Matches(guid, collectionID, environmentID string) bool {
+	return pc.GUID == guid && pc.CollectionID == collectionID && pc.EnvironmentID == environmentID
+}
+
+// Checksum : returns the sha256 of the canonical json, without whitespace and with the keys sorted, so that neither the
+// indentation nor the key order of a stored file changes it
+func Checksum(configurations []byte) string {
+	canonical, err := canonicalJSON(configurations)
+	if err != nil {
+		canonical = configurations
+	}
+	sum := sha256.Sum256(canonical)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// canonicalJSON : returns the json re-encoded with the keys of its objects sorted. Numbers keep their text.
+func canonicalJSON(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
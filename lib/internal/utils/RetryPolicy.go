@@ -0,0 +1,204 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy : Delays between the attempts of a request that fails. The delay before the second attempt is
+// InitialDelay, and every following delay is Multiplier times the previous one, up to MaxDelay. Each delay is then
+// randomised by up to Jitter (a fraction between 0 and 1) of it, in either direction.
+// After MaxAttempts attempts the request is given up until the next retry cycle, which starts after MaxDelay.
+type RetryPolicy struct {
+	InitialDelay time.Duration
+	Multiplier   float64
+	MaxDelay     time.Duration
+	Jitter       float64
+	MaxAttempts  int
+}
+
+// DefaultRetryPolicy : Retry policy used when none is given
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		InitialDelay: time.Second,
+		Multiplier:   2,
+		MaxDelay:     10 * time.Minute,
+		Jitter:       0.2,
+		MaxAttempts:  3,
+	}
+}
+
+// normalize : Replace the values out of range. A Multiplier below 1 is 1, a MaxDelay that is not positive is the one of
+// the default policy, Jitter is bounded to [0, 1] and MaxAttempts is at least 1.
+func (p RetryPolicy) normalize() RetryPolicy {
+	if p.InitialDelay < 0 {
+		p.InitialDelay = 0
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = 1
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultRetryPolicy().MaxDelay
+	}
+	if p.Jitter < 0 {
+		p.Jitter = 0
+	} else if p.Jitter > 1 {
+		p.Jitter = 1
+	}
+	if p.MaxAttempts < 1 {
+		p.MaxAttempts = 1
+	}
+	return p
+}
+
+// Delay : Delay after the given number of failed attempts, before jitter is applied
+func (p RetryPolicy) Delay(failedAttempts int) time.Duration {
+	p = p.normalize()
+	if failedAttempts < 1 {
+		return 0
+	}
+	delay := float64(p.InitialDelay) * math.Pow(p.Multiplier, float64(failedAttempts-1))
+	if delay > float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+	return time.Duration(delay)
+}
+
+// CycleDelay : Delay before the next retry cycle, once MaxAttempts attempts have failed
+func (p RetryPolicy) CycleDelay() time.Duration {
+	return p.normalize().MaxDelay
+}
+
+// jitter : Randomise the delay by up to Jitter of it. random returns a number in [0, 1).
+func (p RetryPolicy) jitter(delay time.Duration, random func() float64) time.Duration {
+	p = p.normalize()
+	return jitterDelay(delay, p.Jitter, random)
+}
+
+// Jitter : Randomise the delay by up to the fraction jitter (between 0 and 1) of it, in either direction
+func Jitter(delay time.Duration, jitter float64) time.Duration {
+	if jitter < 0 {
+		jitter = 0
+	} else if jitter > 1 {
+		jitter = 1
+	}
+	return jitterDelay(delay, jitter, rand.Float64)
+}
+
+// jitterDelay : Randomise the delay by up to jitter of it. random returns a number in [0, 1).
+func jitterDelay(delay time.Duration, jitter float64, random func() float64) time.Duration {
+	return time.Duration(float64(delay) * (1 + jitter*(2*random()-1)))
+}
+
+// Clock : Source of the timers the retries wait on, replaced by a fake one in tests
+type Clock interface {
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// SystemClock : Clock backed by the time package
+var SystemClock Clock = systemClock{}
+
+// Backoff : Attempts of a request following a retry policy
+type Backoff struct {
+	policy         RetryPolicy
+	clock          Clock
+	random         func() float64
+	failedAttempts int
+	unlimited      bool
+}
+
+// NewBackoff : Create a backoff for the policy, waiting on the clock. The system clock is used when clock is nil.
+func NewBackoff(policy RetryPolicy, clock Clock) *Backoff {
+	if clock == nil {
+		clock = SystemClock
+	}
+	return &Backoff{
+		policy: policy.normalize(),
+		clock:  clock,
+		random: rand.Float64,
+	}
+}
+
+// NewReconnectBackoff : Create a backoff for the policy which never gives up: the delay keeps growing up to MaxDelay
+// whatever MaxAttempts, without waiting for a retry cycle. The system clock is used when clock is nil.
+func NewReconnectBackoff(policy RetryPolicy, clock Clock) *Backoff {
+	b := NewBackoff(policy, clock)
+	b.unlimited = true
+	return b
+}
+
+// Next : Record a failed attempt and return the delay before the next one. The second value is false when the
+// attempts of the policy are used, the backoff is then reset and the delay is the one before the next retry cycle.
+// A reconnect backoff never uses its attempts.
+func (b *Backoff) Next() (time.Duration, bool) {
+	b.failedAttempts++
+	if b.unlimited {
+		return b.policy.jitter(b.policy.Delay(b.failedAttempts), b.random), true
+	}
+	if b.failedAttempts >= b.policy.MaxAttempts {
+		b.Reset()
+		return b.policy.jitter(b.policy.CycleDelay(), b.random), false
+	}
+	return b.policy.jitter(b.policy.Delay(b.failedAttempts), b.random), true
+}
+
+// Reset : Forget the failed attempts, after a successful one
+func (b *Backoff) Reset() {
+	b.failedAttempts = 0
+}
+
+// Wait : Wait for the delay. Returns ctx.Err() when ctx is done before that.
+func (b *Backoff) Wait(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	select {
+	case <-b.clock.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Retry : Call f until it succeeds or the attempts of the policy are used, waiting between the attempts.
+// Returns the last error of f, also when ctx is done during an attempt or while waiting.
+func Retry(ctx context.Context, policy RetryPolicy, clock Clock, f func() error) error {
+	backoff := NewBackoff(policy, clock)
+	for {
+		err := f()
+		if err == nil {
+			return nil
+		}
+		delay, ok := backoff.Next()
+		if !ok {
+			return err
+		}
+		if waitErr := backoff.Wait(ctx, delay); waitErr != nil || ctx.Err() != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,80 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/gorilla/websocket"
+)
+
+// TransportOptions : HTTP client, request timeout and additional headers of the requests sent to the App Configuration service.
+// The zero value uses a default pooled HTTP client without a timeout and sends no additional headers.
+type TransportOptions struct {
+	HTTPClient     *http.Client
+	RequestTimeout time.Duration
+	Headers        http.Header
+}
+
+// Client : returns the HTTP client of the options, copied with the request timeout applied when one is set.
+func (to TransportOptions) Client() *http.Client {
+	client := to.HTTPClient
+	if client == nil {
+		client = core.DefaultHTTPClient()
+	}
+	if to.RequestTimeout > 0 {
+		// copy the client so that the timeout of the client handed in by the caller is not changed
+		timeoutClient := *client
+		timeoutClient.Timeout = to.RequestTimeout
+		client = &timeoutClient
+	}
+	return client
+}
+
+// WebSocketDialer : returns a websocket dialer which connects through the proxy, with the TLS configuration and the dialer
+// of the HTTP client of the options, and which times the handshake out after the request timeout.
+func (to TransportOptions) WebSocketDialer() *websocket.Dialer {
+	dialer := *websocket.DefaultDialer
+	if to.HTTPClient != nil {
+		if transport, ok := to.HTTPClient.Transport.(*http.Transport); ok {
+			dialer.Proxy = transport.Proxy
+			dialer.TLSClientConfig = transport.TLSClientConfig
+			dialer.NetDialContext = transport.DialContext
+		}
+	}
+	if to.RequestTimeout > 0 {
+		dialer.HandshakeTimeout = to.RequestTimeout
+	}
+	return &dialer
+}
+
+// AddHeaders : sets the additional headers of the options on the given header and returns it. Their values replace the
+// ones of the SDK for the same names, such as User-Agent, except for Authorization which is left to the authenticator.
+func (to TransportOptions) AddHeaders(header http.Header) http.Header {
+	for key, values := range to.Headers {
+		if http.CanonicalHeaderKey(key) == "Authorization" {
+			continue
+		}
+		header.Del(key)
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+	return header
+}
@@ -19,6 +19,7 @@ package utils
 import (
 	"net/http"
 	"regexp"
+	"strings"
 
 	"github.com/IBM/go-sdk-core/v5/core"
 )
@@ -34,6 +35,8 @@ type URLBuilder struct {
 	events        string
 	region        string
 	guid          string
+	collectionID  string
+	environmentID string
 	iamURL        string
 	authenticator core.Authenticator
 }
@@ -43,38 +46,44 @@ var urlBuilderInstance *URLBuilder
 // GetInstance : Get Instance
 func GetInstance() *URLBuilder {
 	if urlBuilderInstance == nil {
-		urlBuilderInstance = &URLBuilder{
-			baseURL:       ".apprapp.cloud.ibm.com",
-			wsURL:         "/wsfeature",
-			path:          "/feature/v1/instances/",
-			service:       "/apprapp",
-			httpBase:      "https://",
-			webSocketBase: "wss://",
-			events:        "/events/v1/instances/",
-			region:        "",
-			guid:          "",
-			iamURL:        "https://iam.cloud.ibm.com",
-		}
+		urlBuilderInstance = NewURLBuilder()
 	}
 	return urlBuilderInstance
 }
 
+// NewURLBuilder : returns a new URLBuilder which is not shared with the instance returned by GetInstance.
+func NewURLBuilder() *URLBuilder {
+	return &URLBuilder{
+		baseURL:       ".apprapp.cloud.ibm.com",
+		wsURL:         "/wsfeature",
+		path:          "/feature/v1/instances/",
+		service:       "/apprapp",
+		httpBase:      "https://",
+		webSocketBase: "wss://",
+		events:        "/events/v1/instances/",
+		region:        "",
+		guid:          "",
+		iamURL:        "https://iam.cloud.ibm.com",
+	}
+}
+
 // Init : Init
 func (ub *URLBuilder) Init(collectionID string, environmentID string, region string, guid string, apikey string, overrideServerHost string) {
 	ub.region = region
 	ub.guid = guid
+	ub.collectionID = collectionID
+	ub.environmentID = environmentID
+	// start from the defaults so that Init can be called again when the context is set a second time
+	ub.iamURL = "https://iam.cloud.ibm.com"
 	if len(overrideServerHost) > 0 {
 		ub.httpBase = overrideServerHost
 		ub.iamURL = "https://iam.test.cloud.ibm.com"
 		var compile, _ = regexp.Compile(`http([a-z]*)://`)
-		ub.webSocketBase += compile.ReplaceAllString(overrideServerHost, "")
+		ub.webSocketBase = ub.webSocketURL("wss://" + compile.ReplaceAllString(overrideServerHost, ""))
 	} else {
-		ub.httpBase += region
-		ub.httpBase += ub.baseURL
-		ub.webSocketBase += region
-		ub.webSocketBase += ub.baseURL
+		ub.httpBase = "https://" + region + ub.baseURL
+		ub.webSocketBase = ub.webSocketURL("wss://" + region + ub.baseURL)
 	}
-	ub.webSocketBase += ub.service + ub.wsURL + "?instance_id=" + guid + "&collection_id=" + collectionID + "&environment_id=" + environmentID
 	// Create the authenticator.
 	ub.authenticator = &core.IamAuthenticator{
 		ApiKey: apikey,
@@ -82,12 +91,49 @@ func (ub *URLBuilder) Init(collectionID string, environmentID string, region str
 	}
 }
 
+// UsePrivateEndpoint : switches the service and web socket urls to the private endpoint of the region,
+// private.<region>.apprapp.cloud.ibm.com, and the IAM url to the private IAM endpoint.
+func (ub *URLBuilder) UsePrivateEndpoint() {
+	ub.httpBase = "https://private." + ub.region + ub.baseURL
+	ub.webSocketBase = ub.webSocketURL("wss://private." + ub.region + ub.baseURL)
+	ub.SetIAMURL("https://private.iam.cloud.ibm.com")
+}
+
+// SetBaseURLs : sets the base urls of the service and of the web socket, such as http://localhost:8080 and ws://localhost:8080.
+// When the web socket base url is empty, it is the service base url with the http scheme replaced by ws and https by wss.
+func (ub *URLBuilder) SetBaseURLs(httpBase string, webSocketBase string) {
+	httpBase = strings.TrimSuffix(httpBase, "/")
+	if len(webSocketBase) == 0 {
+		webSocketBase = "ws" + strings.TrimPrefix(httpBase, "http")
+	}
+	ub.httpBase = httpBase
+	ub.webSocketBase = ub.webSocketURL(strings.TrimSuffix(webSocketBase, "/"))
+}
+
+// webSocketURL : returns the web socket url of the context on the given web socket base url
+func (ub *URLBuilder) webSocketURL(webSocketBase string) string {
+	return webSocketBase + ub.service + ub.wsURL + "?instance_id=" + ub.guid + "&collection_id=" + ub.collectionID + "&environment_id=" + ub.environmentID
+}
+
 // GetBaseServiceURL returns base service url
 func (ub *URLBuilder) GetBaseServiceURL() string {
 	return ub.httpBase
 }
 
-// GetAuthenticator returns iam authenticator
+// SetIAMURL : sets the url of the IAM token service the authenticator created by Init exchanges the API key at
+func (ub *URLBuilder) SetIAMURL(iamURL string) {
+	ub.iamURL = iamURL
+	if authenticator, ok := ub.authenticator.(*core.IamAuthenticator); ok {
+		authenticator.URL = iamURL
+	}
+}
+
+// GetIAMURL : returns the url of the IAM token service
+func (ub *URLBuilder) GetIAMURL() string {
+	return ub.iamURL
+}
+
+// GetAuthenticator returns the authenticator of the requests, the IAM authenticator created by Init unless one was set
 func (ub *URLBuilder) GetAuthenticator() core.Authenticator {
 	return ub.authenticator
 }
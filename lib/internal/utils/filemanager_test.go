@@ -17,10 +17,16 @@
 package utils
 
 import (
-	"github.com/IBM/appconfiguration-go-sdk/lib/internal/constants"
-	"github.com/stretchr/testify/assert"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"path"
+	"sync"
 	"testing"
+
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/constants"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestFileManager(t *testing.T) {
@@ -42,3 +48,29 @@ func TestFileManager(t *testing.T) {
 	// TestReadFilesWithNonExistingFile
 	assert.Equal(t, ReadFiles("non-existing-file.txt"), []byte(`{}`))
 }
+
+func TestStoreFilesAtomically(t *testing.T) {
+	mockLogger()
+	dir := t.TempDir()
+
+	// concurrent writers always leave one complete file behind, and no temporary file
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			StoreFiles(fmt.Sprintf(`{"writer":%d,"padding":"%0*d"}`, i, i*100, 0), dir)
+		}(i)
+	}
+	wg.Wait()
+	data := ReadFiles(path.Join(dir, constants.ConfigurationFile))
+	assert.True(t, json.Valid(data))
+	assert.NotEqual(t, `{}`, string(data))
+	files, _ := ioutil.ReadDir(dir)
+	assert.Equal(t, 1, len(files))
+
+	// a truncated file is read as an empty json object
+	assert.Nil(t, ioutil.WriteFile(path.Join(dir, constants.ConfigurationFile), []byte(`{"features":[`), 0644))
+	assert.Equal(t, []byte(`{}`), ReadFiles(path.Join(dir, constants.ConfigurationFile)))
+	assert.Equal(t, "AppConfiguration - The file is not a valid json and is ignored "+path.Join(dir, constants.ConfigurationFile), hook.LastEntry().Message)
+}
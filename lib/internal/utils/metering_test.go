@@ -18,9 +18,13 @@ package utils
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"runtime"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/IBM/go-sdk-core/v5/core"
 
@@ -221,6 +225,8 @@ func TestSendToServer(t *testing.T) {
 
 	m := GetMeteringInstance()
 	m.Init("guid", "dev", "c1")
+	recorder := &meteringBatchRecorder{}
+	m.SetMetricsRecorder(recorder)
 	urlBuilderInstance = &URLBuilder{
 
 		httpBase: ts.URL,
@@ -250,10 +256,11 @@ func TestSendToServer(t *testing.T) {
 
 	m.buildRequestBody(m.meteringFeatureData, guidMap, "feature_id")
 	assert.Equal(t, int64(2), guidMap["guid"][0].Usages[0].Count)
-	m.sendToServer("guid", guidMap["guid"][0])
+	m.sendToServer(m.ctx, "guid", guidMap["guid"][0])
 	if hook.LastEntry().Message != "AppConfiguration - Successfully sent metering data to server." {
 		t.Errorf("Test failed: Incorrect error message")
 	}
+	assert.Equal(t, 1, recorder.sent)
 	ts.Close()
 	resetMeteringInstance()
 
@@ -261,8 +268,10 @@ func TestSendToServer(t *testing.T) {
 
 	mockLogger()
 	log.SetLogLevel("debug")
+	requests := 0
 	ts = httptest.NewServer(
 		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
 			w.WriteHeader(500)
 
 		}))
@@ -271,15 +280,148 @@ func TestSendToServer(t *testing.T) {
 		httpBase: ts.URL,
 	}
 	urlBuilderInstance.SetAuthenticator(&core.NoAuthAuthenticator{})
-	m.sendToServer("guid", guidMap["guid"][0])
-	if hook.LastEntry().Message != "AppConfiguration - Error while sending metering data to server <nil>" {
+	m.SetRetryPolicy(RetryPolicy{MaxAttempts: 3}, nil)
+	m.sendToServer(m.ctx, "guid", guidMap["guid"][0])
+	if hook.LastEntry().Message != "AppConfiguration - Error while sending metering data to server status code 500" {
 		t.Errorf("Test failed: Incorrect error message -->")
 	}
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, 1, recorder.sent)
+	assert.Equal(t, 1, recorder.dropped)
 	resetMeteringInstance()
 
 }
+
+func TestMeteringCloseAbortsRequest(t *testing.T) {
+	requests := make(chan struct{}, 10)
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests <- struct{}{}
+			// the request is answered only once the client gives it up, which the server sees once the body is read
+			ioutil.ReadAll(r.Body)
+			<-r.Context().Done()
+		}))
+	defer ts.Close()
+	urlBuilderInstance = &URLBuilder{
+		httpBase: ts.URL,
+	}
+	urlBuilderInstance.SetAuthenticator(&core.NoAuthAuthenticator{})
+	m := NewMetering(nil, nil)
+	m.Init("guid", "dev", "c1")
+	recorder := &meteringBatchRecorder{}
+	m.SetMetricsRecorder(recorder)
+	m.SetRetryPolicy(RetryPolicy{MaxAttempts: 3}, blockingClock{})
+
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		m.sendToServer(m.ctx, "guid", CollectionUsages{CollectionID: "c1", EnvironmentID: "dev", Usages: []Usages{{FeatureID: "f1", EntityID: "e1", Count: 1}}})
+	}()
+	<-requests
+	m.Close()
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Test failed: metering request not aborted by close")
+	}
+	assert.Equal(t, 0, len(requests))
+	assert.Equal(t, 1, recorder.dropped)
+	resetMeteringInstance()
+}
+
+func TestMeteringCloseCancelsRetries(t *testing.T) {
+	requests := make(chan struct{}, 10)
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests <- struct{}{}
+			w.WriteHeader(500)
+		}))
+	defer ts.Close()
+	urlBuilderInstance = &URLBuilder{
+		httpBase: ts.URL,
+	}
+	urlBuilderInstance.SetAuthenticator(&core.NoAuthAuthenticator{})
+	m := NewMetering(nil, nil)
+	m.Init("guid", "dev", "c1")
+	recorder := &meteringBatchRecorder{}
+	m.SetMetricsRecorder(recorder)
+	// the retries wait forever unless cancelled
+	m.SetRetryPolicy(RetryPolicy{MaxAttempts: 3}, blockingClock{})
+
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		m.sendToServer(m.ctx, "guid", CollectionUsages{CollectionID: "c1", EnvironmentID: "dev", Usages: []Usages{{FeatureID: "f1", EntityID: "e1", Count: 1}}})
+	}()
+	<-requests
+	m.Close()
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Test failed: metering retry not cancelled by close")
+	}
+	assert.Equal(t, 0, len(requests))
+	assert.Equal(t, 1, recorder.dropped)
+	resetMeteringInstance()
+}
+
+// meteringBatchRecorder : Metrics recorder counting the metering batches sent and dropped
+type meteringBatchRecorder struct {
+	NopMetricsRecorder
+	sent    int
+	dropped int
+}
+
+func (r *meteringBatchRecorder) RecordMeteringBatchSent() {
+	r.sent++
+}
+
+func (r *meteringBatchRecorder) RecordMeteringBatchDropped() {
+	r.dropped++
+}
+
 func resetMeteringInstance() {
 	meteringInstance = nil
 	urlBuilderInstance = nil
 	log.SetLogLevel("info")
 }
+
+func TestMeteringStart(t *testing.T) {
+	// the metering data is sent in the background only once started, and closing stops it
+	before := runtime.NumGoroutine()
+	m := NewMetering(nil, nil)
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+	m.Start()
+	m.Start()
+	assert.Greater(t, runtime.NumGoroutine(), before)
+	m.Close()
+	m.Start()
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+}
+
+func TestMeteringRecordEvaluation(t *testing.T) {
+	// the recorder can be replaced while evaluations are recorded
+	m := NewMetering(nil, nil)
+	m.Init("guid", "dev", "c1")
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				m.RecordEvaluation("f1", "", "e1", "s1")
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		m.SetMetricsRecorder(&meteringBatchRecorder{})
+		m.SetMetricsRecorder(nil)
+	}
+	wg.Wait()
+	assert.Equal(t, int64(400), m.meteringFeatureData["guid"]["dev"]["c1"]["f1"]["e1"]["s1"].count)
+	assert.Equal(t, NopMetricsRecorder{}, m.getMetricsRecorder())
+}
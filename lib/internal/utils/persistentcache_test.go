@@ -0,0 +1,68 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/IBM/appconfiguration-go-sdk/lib/internal/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistentCache(t *testing.T) {
+	fetchedAt := time.Date(2021, 6, 8, 3, 38, 38, 0, time.UTC)
+	configurations := []byte(`{"features":[],"properties":[],"segments":[]}`)
+	persistentCache := NewPersistentCache("guid", "c1", "dev", `"etag"`, fetchedAt, configurations)
+	assert.Equal(t, constants.SDKVersion, persistentCache.SDKVersion)
+	assert.True(t, persistentCache.Matches("guid", "c1", "dev"))
+	assert.False(t, persistentCache.Matches("guid", "c1", "prod"))
+
+	// the checksum still matches once the envelope is indented, as StoreFiles does
+	data, _ := json.Marshal(persistentCache)
+	indented, _ := json.MarshalIndent(json.RawMessage(data), "", "\t")
+	parsed, err := ParsePersistentCache(indented)
+	assert.Nil(t, err)
+	assert.Equal(t, "guid", parsed.GUID)
+	assert.Equal(t, "c1", parsed.CollectionID)
+	assert.Equal(t, "dev", parsed.EnvironmentID)
+	assert.Equal(t, `"etag"`, parsed.ETag)
+	assert.True(t, fetchedAt.Equal(parsed.FetchedAt))
+
+	// configurations which were changed are rejected
+	persistentCache.Configurations = []byte(`{"features":[{}],"properties":[],"segments":[]}`)
+	data, _ = json.Marshal(persistentCache)
+	_, err = ParsePersistentCache(data)
+	assert.Equal(t, ErrPersistentCacheChecksum, err)
+
+	// the raw configurations written by the previous versions are not an envelope
+	_, err = ParsePersistentCache(configurations)
+	assert.Equal(t, ErrPersistentCacheFormat, err)
+	_, err = ParsePersistentCache([]byte(`[]`))
+	assert.Equal(t, ErrPersistentCacheFormat, err)
+}
+
+func TestChecksum(t *testing.T) {
+	// neither the whitespace nor the key order changes the checksum
+	checksum := Checksum([]byte(`{"features":[{"name":"F","feature_id":"f","enabled_value":1.50}],"properties":[]}`))
+	assert.Equal(t, checksum, Checksum([]byte("{\n  \"properties\": [],\n  \"features\": [ { \"feature_id\": \"f\", \"enabled_value\": 1.50, \"name\": \"F\" } ]\n}")))
+
+	// the values do
+	assert.NotEqual(t, checksum, Checksum([]byte(`{"features":[{"name":"F","feature_id":"f","enabled_value":1.5}],"properties":[]}`)))
+	assert.NotEqual(t, checksum, Checksum([]byte(`{"features":[{"name":"G","feature_id":"f","enabled_value":1.50}],"properties":[]}`)))
+}
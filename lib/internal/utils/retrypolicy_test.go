@@ -0,0 +1,170 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock : Clock firing every timer at once and recording the delays waited for
+type fakeClock struct {
+	delays []time.Duration
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.delays = append(c.delays, d)
+	fired := make(chan time.Time, 1)
+	fired <- time.Time{}
+	return fired
+}
+
+// blockingClock : Clock whose timers never fire
+type blockingClock struct{}
+
+func (blockingClock) After(d time.Duration) <-chan time.Time {
+	return make(chan time.Time)
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{InitialDelay: time.Second, Multiplier: 2, MaxDelay: 5 * time.Second, MaxAttempts: 10}
+	assert.Equal(t, time.Duration(0), policy.Delay(0))
+	assert.Equal(t, time.Second, policy.Delay(1))
+	assert.Equal(t, 2*time.Second, policy.Delay(2))
+	assert.Equal(t, 4*time.Second, policy.Delay(3))
+	assert.Equal(t, 5*time.Second, policy.Delay(4))
+	assert.Equal(t, 5*time.Second, policy.CycleDelay())
+
+	// out of range values
+	policy = RetryPolicy{InitialDelay: time.Second, Multiplier: 0.5}
+	assert.Equal(t, time.Second, policy.Delay(3))
+	assert.Equal(t, DefaultRetryPolicy().MaxDelay, policy.CycleDelay())
+	assert.Equal(t, 1, policy.normalize().MaxAttempts)
+	assert.Equal(t, float64(1), RetryPolicy{Jitter: 3}.normalize().Jitter)
+
+	// jitter
+	assert.Equal(t, time.Second, Jitter(time.Second, -1))
+	for i := 0; i < 100; i++ {
+		delay := Jitter(time.Second, 0.1)
+		assert.True(t, delay >= 900*time.Millisecond && delay <= 1100*time.Millisecond)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	clock := &fakeClock{}
+	backoff := NewBackoff(RetryPolicy{InitialDelay: time.Second, Multiplier: 3, MaxDelay: time.Minute, Jitter: 0.5, MaxAttempts: 3}, clock)
+
+	// jitter moves the delay by up to half of it, in either direction
+	backoff.random = func() float64 { return 0 }
+	delay, ok := backoff.Next()
+	assert.True(t, ok)
+	assert.Equal(t, 500*time.Millisecond, delay)
+	backoff.random = func() float64 { return 1 }
+	delay, ok = backoff.Next()
+	assert.True(t, ok)
+	assert.Equal(t, 4500*time.Millisecond, delay)
+	// the attempts are used, the next cycle starts after the max delay
+	backoff.random = func() float64 { return 0.5 }
+	delay, ok = backoff.Next()
+	assert.False(t, ok)
+	assert.Equal(t, time.Minute, delay)
+	// and the backoff starts over
+	delay, ok = backoff.Next()
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, delay)
+	backoff.Reset()
+	delay, _ = backoff.Next()
+	assert.Equal(t, time.Second, delay)
+
+	assert.Nil(t, backoff.Wait(context.Background(), 3*time.Second))
+	assert.Equal(t, []time.Duration{3 * time.Second}, clock.delays)
+}
+
+func TestReconnectBackoff(t *testing.T) {
+	backoff := NewReconnectBackoff(RetryPolicy{InitialDelay: time.Second, Multiplier: 2, MaxDelay: 10 * time.Second, MaxAttempts: 3}, nil)
+
+	// the delay keeps doubling past the attempts of the policy, up to the max delay, without a retry cycle
+	var delays []time.Duration
+	for i := 0; i < 6; i++ {
+		delay, ok := backoff.Next()
+		assert.True(t, ok)
+		delays = append(delays, delay)
+	}
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}, delays)
+	backoff.Reset()
+	delay, _ := backoff.Next()
+	assert.Equal(t, time.Second, delay)
+}
+
+func TestRetry(t *testing.T) {
+	policy := RetryPolicy{InitialDelay: 100 * time.Millisecond, Multiplier: 2, MaxDelay: time.Second, MaxAttempts: 4}
+	failure := errors.New("failure")
+
+	// all the attempts fail
+	clock := &fakeClock{}
+	attempts := 0
+	err := Retry(context.Background(), policy, clock, func() error {
+		attempts++
+		return failure
+	})
+	assert.Equal(t, failure, err)
+	assert.Equal(t, 4, attempts)
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond}, clock.delays)
+
+	// the second attempt succeeds
+	clock = &fakeClock{}
+	attempts = 0
+	err = Retry(context.Background(), policy, clock, func() error {
+		attempts++
+		if attempts < 2 {
+			return failure
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, []time.Duration{100 * time.Millisecond}, clock.delays)
+
+	// the context is done while waiting
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts = 0
+	done := make(chan error)
+	go func() {
+		done <- Retry(ctx, policy, blockingClock{}, func() error {
+			attempts++
+			return failure
+		})
+	}()
+	cancel()
+	assert.Equal(t, failure, <-done)
+	assert.Equal(t, 1, attempts)
+
+	// the context is done during an attempt, no other attempt is made even without delay
+	ctx, cancel = context.WithCancel(context.Background())
+	attempts = 0
+	err = Retry(ctx, RetryPolicy{MaxAttempts: 4}, &fakeClock{}, func() error {
+		attempts++
+		cancel()
+		return failure
+	})
+	assert.Equal(t, failure, err)
+	assert.Equal(t, 1, attempts)
+}
@@ -0,0 +1,131 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/go-sdk-core/v5/core"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingTransport : RoundTripper counting the requests it sends
+type countingTransport struct {
+	requests int
+	mu       sync.Mutex
+}
+
+func (ct *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ct.mu.Lock()
+	ct.requests++
+	ct.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestTransportOptions(t *testing.T) {
+	// the client handed in keeps its own timeout
+	client := &http.Client{}
+	transport := TransportOptions{HTTPClient: client, RequestTimeout: time.Second}
+	assert.Equal(t, time.Second, transport.Client().Timeout)
+	assert.Equal(t, time.Duration(0), client.Timeout)
+	assert.NotNil(t, TransportOptions{}.Client())
+
+	// the websocket dialer uses the proxy and TLS configuration of the client
+	proxyURL, _ := url.Parse("http://proxy.example.com:3128")
+	tlsConfig := &tls.Config{ServerName: "example.com"}
+	transport = TransportOptions{
+		HTTPClient:     &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL), TLSClientConfig: tlsConfig}},
+		RequestTimeout: 2 * time.Second,
+	}
+	dialer := transport.WebSocketDialer()
+	proxy, _ := dialer.Proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "example.com"}})
+	assert.Equal(t, proxyURL, proxy)
+	assert.Equal(t, tlsConfig, dialer.TLSClientConfig)
+	assert.Equal(t, 2*time.Second, dialer.HandshakeTimeout)
+
+	header := TransportOptions{Headers: http.Header{"X-Test": []string{"a", "b"}}}.AddHeaders(http.Header{"Authorization": []string{"token"}})
+	assert.Equal(t, []string{"a", "b"}, header.Values("X-Test"))
+	assert.Equal(t, "token", header.Get("Authorization"))
+
+	// the headers of the SDK are replaced, except Authorization
+	header = TransportOptions{Headers: http.Header{"User-Agent": []string{"my-agent"}, "authorization": []string{"other"}}}.AddHeaders(http.Header{"User-Agent": []string{"sdk"}, "Authorization": []string{"token"}})
+	assert.Equal(t, []string{"my-agent"}, header.Values("User-Agent"))
+	assert.Equal(t, []string{"token"}, header.Values("Authorization"))
+}
+
+func TestAPIManagerTransport(t *testing.T) {
+	var mu sync.Mutex
+	var headers []string
+	var userAgents []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		headers = append(headers, r.Header.Get("X-Test"))
+		userAgents = r.Header.Values("User-Agent")
+		mu.Unlock()
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.WriteHeader(200)
+	}))
+	defer ts.Close()
+
+	urlBuilder := NewURLBuilder()
+	urlBuilder.httpBase = ts.URL
+	urlBuilder.SetAuthenticator(&core.NoAuthAuthenticator{})
+	apiManager := NewAPIManager(urlBuilder)
+	roundTripper := &countingTransport{}
+	apiManager.SetTransportOptions(TransportOptions{
+		HTTPClient: &http.Client{Transport: roundTripper},
+		Headers:    http.Header{"X-Test": []string{"value"}, "User-Agent": []string{"my-agent"}},
+	})
+
+	// the base service is created once and reused by the following requests
+	for i := 0; i < 2; i++ {
+		builder := core.NewRequestBuilder(core.GET)
+		builder.ConstructHTTPURL(ts.URL, nil, nil)
+		response := apiManager.Request(builder)
+		assert.Equal(t, 200, response.StatusCode)
+	}
+	baseService := apiManager.baseService
+	builder := core.NewRequestBuilder(core.GET)
+	builder.ConstructHTTPURL(ts.URL, nil, nil)
+	apiManager.Request(builder)
+	assert.True(t, baseService == apiManager.baseService)
+	assert.Equal(t, 3, roundTripper.requests)
+	assert.Equal(t, []string{"value", "value", "value"}, headers)
+
+	// the metering requests go through the same transport
+	metering := NewMetering(urlBuilder, apiManager)
+	metering.Init("guid", "dev", "c1")
+	metering.SetRetryPolicy(RetryPolicy{MaxAttempts: 1}, nil)
+	metering.sendToServer(metering.ctx, "guid", CollectionUsages{CollectionID: "c1", EnvironmentID: "dev"})
+	assert.Equal(t, 4, roundTripper.requests)
+	// with the user agent of the options instead of the one of the SDK
+	assert.Equal(t, []string{"my-agent"}, userAgents)
+
+	// a request taking longer than the request timeout fails
+	apiManager.SetTransportOptions(TransportOptions{RequestTimeout: 50 * time.Millisecond})
+	builder = core.NewRequestBuilder(core.GET)
+	builder.ConstructHTTPURL(ts.URL+"/slow", nil, nil)
+	assert.Nil(t, apiManager.Request(builder))
+}
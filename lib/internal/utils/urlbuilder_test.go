@@ -19,6 +19,7 @@ package utils
 import (
 	"testing"
 
+	"github.com/IBM/go-sdk-core/v5/core"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -36,6 +37,15 @@ func TestURLBuilder(t *testing.T) {
 	assert.Equal(t, "https://region.apprapp.cloud.ibm.com", urlBuilder.GetBaseServiceURL())
 	resetURLBuilderInstance()
 
+	// test when init is called a second time with a different region
+	urlBuilder = NewURLBuilder()
+	urlBuilder.Init("CollectionID", "EnvironmentID", "region", "guid", "apikey", "")
+	urlBuilder.Init("CollectionID", "EnvironmentID", "eu-gb", "guid", "apikey", "")
+	assert.Equal(t, "https://eu-gb.apprapp.cloud.ibm.com", urlBuilder.GetBaseServiceURL())
+	assert.Equal(t, "wss://eu-gb.apprapp.cloud.ibm.com/apprapp/wsfeature?instance_id=guid&collection_id=CollectionID&environment_id=EnvironmentID", urlBuilder.GetWebSocketURL())
+	assert.NotSame(t, GetInstance(), urlBuilder)
+	resetURLBuilderInstance()
+
 	// test when get token encounters an error while retrieving token and returns an token of size 0
 	urlBuilder = GetInstance()
 	urlBuilder.Init("CollectionID", "EnvironmentID", "region", "guid", "apikey", "")
@@ -43,6 +53,37 @@ func TestURLBuilder(t *testing.T) {
 	assert.Equal(t, 0, len(token))
 	resetURLBuilderInstance()
 
+	// test when the private endpoint is used
+	urlBuilder = NewURLBuilder()
+	urlBuilder.Init("CollectionID", "EnvironmentID", "eu-de", "guid", "apikey", "")
+	urlBuilder.UsePrivateEndpoint()
+	assert.Equal(t, "https://private.eu-de.apprapp.cloud.ibm.com", urlBuilder.GetBaseServiceURL())
+	assert.Equal(t, "wss://private.eu-de.apprapp.cloud.ibm.com/apprapp/wsfeature?instance_id=guid&collection_id=CollectionID&environment_id=EnvironmentID", urlBuilder.GetWebSocketURL())
+	assert.Equal(t, "https://private.iam.cloud.ibm.com", urlBuilder.GetIAMURL())
+
+	// test when explicit base urls are set, the web socket one being derived from the http one when empty
+	urlBuilder = NewURLBuilder()
+	urlBuilder.Init("CollectionID", "EnvironmentID", "region", "guid", "apikey", "")
+	urlBuilder.SetBaseURLs("http://localhost:8080/", "")
+	assert.Equal(t, "http://localhost:8080", urlBuilder.GetBaseServiceURL())
+	assert.Equal(t, "ws://localhost:8080/apprapp/wsfeature?instance_id=guid&collection_id=CollectionID&environment_id=EnvironmentID", urlBuilder.GetWebSocketURL())
+	assert.Equal(t, "https://iam.cloud.ibm.com", urlBuilder.GetIAMURL())
+	urlBuilder.SetBaseURLs("https://appconfig.example.com", "wss://events.example.com")
+	assert.Equal(t, "https://appconfig.example.com", urlBuilder.GetBaseServiceURL())
+	assert.Equal(t, "wss://events.example.com/apprapp/wsfeature?instance_id=guid&collection_id=CollectionID&environment_id=EnvironmentID", urlBuilder.GetWebSocketURL())
+
+	// test when the iam url is set
+	urlBuilder = NewURLBuilder()
+	urlBuilder.Init("CollectionID", "EnvironmentID", "region", "guid", "apikey", "overrideServerHost")
+	assert.Equal(t, "https://iam.test.cloud.ibm.com", urlBuilder.GetIAMURL())
+	urlBuilder.SetIAMURL("https://iam.example.com")
+	assert.Equal(t, "https://iam.example.com", urlBuilder.GetIAMURL())
+	assert.Equal(t, "https://iam.example.com", urlBuilder.GetAuthenticator().(*core.IamAuthenticator).URL)
+
+	// test when get token uses the authenticator set
+	urlBuilder.SetAuthenticator(&core.BearerTokenAuthenticator{BearerToken: "token"})
+	assert.Equal(t, "Bearer token", urlBuilder.GetToken())
+
 }
 
 func resetURLBuilderInstance() {
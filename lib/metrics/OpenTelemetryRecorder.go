@@ -0,0 +1,166 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/IBM/appconfiguration-go-sdk/lib"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// OpenTelemetryRecorder : Metrics recorder exporting the measurements of the clients as OpenTelemetry instruments. One
+// recorder can be shared by several clients.
+type OpenTelemetryRecorder struct {
+	featureEvaluations     metric.Int64Counter
+	propertyEvaluations    metric.Int64Counter
+	fetchAttempts          metric.Int64Counter
+	fetchFailures          metric.Int64Counter
+	fetchDuration          metric.Float64Histogram
+	webSocketReconnects    metric.Int64Counter
+	listenerDuration       metric.Float64Histogram
+	meteringBatchesSent    metric.Int64Counter
+	meteringBatchesDropped metric.Int64Counter
+	mu                     sync.Mutex
+	cacheSizes             map[cacheScope]cacheSize
+}
+
+// cacheScope : Collection and environment the configurations in use belong to
+type cacheScope struct {
+	collectionID  string
+	environmentID string
+}
+
+// cacheSize : Number of features, properties and segments in the configurations in use
+type cacheSize struct {
+	features   int64
+	properties int64
+	segments   int64
+}
+
+var _ lib.MetricsRecorder = (*OpenTelemetryRecorder)(nil)
+
+// NewOpenTelemetryRecorder : Create a recorder whose instruments are created with meter, such as
+// otel.Meter("github.com/IBM/appconfiguration-go-sdk"). Returns the first error creating an instrument.
+func NewOpenTelemetryRecorder(meter metric.Meter) (*OpenTelemetryRecorder, error) {
+	r := &OpenTelemetryRecorder{cacheSizes: make(map[cacheScope]cacheSize)}
+	var err error
+	int64Counter := func(name, description string) metric.Int64Counter {
+		if err != nil {
+			return nil
+		}
+		var counter metric.Int64Counter
+		counter, err = meter.Int64Counter(name, metric.WithDescription(description))
+		return counter
+	}
+	float64Histogram := func(name, description string) metric.Float64Histogram {
+		if err != nil {
+			return nil
+		}
+		var histogram metric.Float64Histogram
+		histogram, err = meter.Float64Histogram(name, metric.WithDescription(description), metric.WithUnit("s"))
+		return histogram
+	}
+	r.featureEvaluations = int64Counter(namespace+".feature.evaluations", "Number of evaluations of each feature, by segment matched (empty when none).")
+	r.propertyEvaluations = int64Counter(namespace+".property.evaluations", "Number of evaluations of each property, by segment matched (empty when none).")
+	r.fetchAttempts = int64Counter(namespace+".fetch.attempts", "Number of attempts to fetch the configurations from the server.")
+	r.fetchFailures = int64Counter(namespace+".fetch.failures", "Number of attempts to fetch the configurations from the server that failed.")
+	r.fetchDuration = float64Histogram(namespace+".fetch.duration", "Duration of the attempts to fetch the configurations from the server.")
+	r.webSocketReconnects = int64Counter(namespace+".websocket.reconnects", "Number of times the websocket got connected again after it was disconnected.")
+	r.listenerDuration = float64Histogram(namespace+".listener.duration", "Time the configuration update listeners take to handle an update.")
+	r.meteringBatchesSent = int64Counter(namespace+".metering.batches.sent", "Number of batches of usage data sent to the server.")
+	r.meteringBatchesDropped = int64Counter(namespace+".metering.batches.dropped", "Number of batches of usage data that could not be sent to the server.")
+	if err != nil {
+		return nil, err
+	}
+	_, err = meter.Int64ObservableGauge(namespace+".cache.entries",
+		metric.WithDescription("Number of features, properties and segments in the configurations in use, by collection and environment."),
+		metric.WithInt64Callback(r.observeCacheSize))
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// RecordFeatureEvaluation : Count the evaluation of the feature
+func (r *OpenTelemetryRecorder) RecordFeatureEvaluation(featureID string, segmentID string) {
+	r.featureEvaluations.Add(context.Background(), 1, metric.WithAttributes(
+		attribute.String("feature_id", featureID), attribute.String("segment_id", segmentID)))
+}
+
+// RecordPropertyEvaluation : Count the evaluation of the property
+func (r *OpenTelemetryRecorder) RecordPropertyEvaluation(propertyID string, segmentID string) {
+	r.propertyEvaluations.Add(context.Background(), 1, metric.WithAttributes(
+		attribute.String("property_id", propertyID), attribute.String("segment_id", segmentID)))
+}
+
+// RecordFetch : Count the fetch attempt, and its failure when err is not nil, and record its duration
+func (r *OpenTelemetryRecorder) RecordFetch(duration time.Duration, err error) {
+	ctx := context.Background()
+	r.fetchAttempts.Add(ctx, 1)
+	if err != nil {
+		r.fetchFailures.Add(ctx, 1)
+	}
+	r.fetchDuration.Record(ctx, duration.Seconds())
+}
+
+// RecordWebSocketReconnect : Count the websocket reconnection
+func (r *OpenTelemetryRecorder) RecordWebSocketReconnect() {
+	r.webSocketReconnects.Add(context.Background(), 1)
+}
+
+// RecordCacheSize : Set the number of features, properties and segments of the collection and environment reported by
+// the cache entries gauge
+func (r *OpenTelemetryRecorder) RecordCacheSize(collectionID string, environmentID string, features int, properties int, segments int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cacheSizes[cacheScope{collectionID, environmentID}] = cacheSize{int64(features), int64(properties), int64(segments)}
+}
+
+// observeCacheSize : Report the last cache size recorded for every collection and environment
+func (r *OpenTelemetryRecorder) observeCacheSize(_ context.Context, observer metric.Int64Observer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for scope, size := range r.cacheSizes {
+		observe := func(value int64, kind string) {
+			observer.Observe(value, metric.WithAttributes(attribute.String("collection_id", scope.collectionID),
+				attribute.String("environment_id", scope.environmentID), attribute.String("kind", kind)))
+		}
+		observe(size.features, "features")
+		observe(size.properties, "properties")
+		observe(size.segments, "segments")
+	}
+	return nil
+}
+
+// RecordListenerLatency : Record the time the listeners took to handle an update
+func (r *OpenTelemetryRecorder) RecordListenerLatency(duration time.Duration) {
+	r.listenerDuration.Record(context.Background(), duration.Seconds())
+}
+
+// RecordMeteringBatchSent : Count the batch of usage data sent
+func (r *OpenTelemetryRecorder) RecordMeteringBatchSent() {
+	r.meteringBatchesSent.Add(context.Background(), 1)
+}
+
+// RecordMeteringBatchDropped : Count the batch of usage data dropped
+func (r *OpenTelemetryRecorder) RecordMeteringBatchDropped() {
+	r.meteringBatchesDropped.Add(context.Background(), 1)
+}
@@ -0,0 +1,166 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/IBM/appconfiguration-go-sdk/lib"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "appconfiguration"
+
+// PrometheusRecorder : Metrics recorder exporting the measurements of the clients as Prometheus metrics. One recorder
+// can be shared by several clients.
+type PrometheusRecorder struct {
+	featureEvaluations     *prometheus.CounterVec
+	propertyEvaluations    *prometheus.CounterVec
+	fetchAttempts          prometheus.Counter
+	fetchFailures          prometheus.Counter
+	fetchDuration          prometheus.Histogram
+	webSocketReconnects    prometheus.Counter
+	cacheEntries           *prometheus.GaugeVec
+	listenerDuration       prometheus.Histogram
+	meteringBatchesSent    prometheus.Counter
+	meteringBatchesDropped prometheus.Counter
+}
+
+var _ lib.MetricsRecorder = (*PrometheusRecorder)(nil)
+
+// NewPrometheusRecorder : Create a recorder whose metrics are registered with registerer, prometheus.DefaultRegisterer
+// when nil. Returns the error of the registration, such as a prometheus.AlreadyRegisteredError when the registerer
+// already has a recorder.
+func NewPrometheusRecorder(registerer prometheus.Registerer) (*PrometheusRecorder, error) {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+	r := &PrometheusRecorder{
+		featureEvaluations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "feature_evaluations_total",
+			Help:      "Number of evaluations of each feature, by segment matched (empty when none).",
+		}, []string{"feature_id", "segment_id"}),
+		propertyEvaluations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "property_evaluations_total",
+			Help:      "Number of evaluations of each property, by segment matched (empty when none).",
+		}, []string{"property_id", "segment_id"}),
+		fetchAttempts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "fetch_attempts_total",
+			Help:      "Number of attempts to fetch the configurations from the server.",
+		}),
+		fetchFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "fetch_failures_total",
+			Help:      "Number of attempts to fetch the configurations from the server that failed.",
+		}),
+		fetchDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "fetch_duration_seconds",
+			Help:      "Duration of the attempts to fetch the configurations from the server.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		webSocketReconnects: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "websocket_reconnects_total",
+			Help:      "Number of times the websocket got connected again after it was disconnected.",
+		}),
+		cacheEntries: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "cache_entries",
+			Help:      "Number of features, properties and segments in the configurations in use, by collection and environment.",
+		}, []string{"collection_id", "environment_id", "kind"}),
+		listenerDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "listener_duration_seconds",
+			Help:      "Time the configuration update listeners take to handle an update.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		meteringBatchesSent: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "metering_batches_sent_total",
+			Help:      "Number of batches of usage data sent to the server.",
+		}),
+		meteringBatchesDropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "metering_batches_dropped_total",
+			Help:      "Number of batches of usage data that could not be sent to the server.",
+		}),
+	}
+	collectors := []prometheus.Collector{
+		r.featureEvaluations, r.propertyEvaluations, r.fetchAttempts, r.fetchFailures, r.fetchDuration,
+		r.webSocketReconnects, r.cacheEntries, r.listenerDuration, r.meteringBatchesSent, r.meteringBatchesDropped,
+	}
+	for i, collector := range collectors {
+		if err := registerer.Register(collector); err != nil {
+			// leave the registerer as it was
+			for _, registered := range collectors[:i] {
+				registerer.Unregister(registered)
+			}
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// RecordFeatureEvaluation : Count the evaluation of the feature
+func (r *PrometheusRecorder) RecordFeatureEvaluation(featureID string, segmentID string) {
+	r.featureEvaluations.WithLabelValues(featureID, segmentID).Inc()
+}
+
+// RecordPropertyEvaluation : Count the evaluation of the property
+func (r *PrometheusRecorder) RecordPropertyEvaluation(propertyID string, segmentID string) {
+	r.propertyEvaluations.WithLabelValues(propertyID, segmentID).Inc()
+}
+
+// RecordFetch : Count the fetch attempt, and its failure when err is not nil, and observe its duration
+func (r *PrometheusRecorder) RecordFetch(duration time.Duration, err error) {
+	r.fetchAttempts.Inc()
+	if err != nil {
+		r.fetchFailures.Inc()
+	}
+	r.fetchDuration.Observe(duration.Seconds())
+}
+
+// RecordWebSocketReconnect : Count the websocket reconnection
+func (r *PrometheusRecorder) RecordWebSocketReconnect() {
+	r.webSocketReconnects.Inc()
+}
+
+// RecordCacheSize : Set the number of features, properties and segments in use for the collection and environment
+func (r *PrometheusRecorder) RecordCacheSize(collectionID string, environmentID string, features int, properties int, segments int) {
+	r.cacheEntries.WithLabelValues(collectionID, environmentID, "features").Set(float64(features))
+	r.cacheEntries.WithLabelValues(collectionID, environmentID, "properties").Set(float64(properties))
+	r.cacheEntries.WithLabelValues(collectionID, environmentID, "segments").Set(float64(segments))
+}
+
+// RecordListenerLatency : Observe the time the listeners took to handle an update
+func (r *PrometheusRecorder) RecordListenerLatency(duration time.Duration) {
+	r.listenerDuration.Observe(duration.Seconds())
+}
+
+// RecordMeteringBatchSent : Count the batch of usage data sent
+func (r *PrometheusRecorder) RecordMeteringBatchSent() {
+	r.meteringBatchesSent.Inc()
+}
+
+// RecordMeteringBatchDropped : Count the batch of usage data dropped
+func (r *PrometheusRecorder) RecordMeteringBatchDropped() {
+	r.meteringBatchesDropped.Inc()
+}
@@ -0,0 +1,173 @@
+/**
+ * (C) Copyright IBM Corp. 2021.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IBM/appconfiguration-go-sdk/lib"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// record : Record one measurement of every kind, and a second feature evaluation, fetch and cache size, the latter of a
+// client of another environment sharing the recorder
+func record(r lib.MetricsRecorder) {
+	r.RecordFeatureEvaluation("discount", "gold")
+	r.RecordFeatureEvaluation("discount", "")
+	r.RecordPropertyEvaluation("banner", "gold")
+	r.RecordFetch(100*time.Millisecond, nil)
+	r.RecordFetch(200*time.Millisecond, errors.New("status code 500"))
+	r.RecordWebSocketReconnect()
+	r.RecordCacheSize("c1", "dev", 3, 2, 1)
+	r.RecordCacheSize("c1", "prod", 5, 4, 0)
+	r.RecordListenerLatency(time.Millisecond)
+	r.RecordMeteringBatchSent()
+	r.RecordMeteringBatchDropped()
+}
+
+func TestPrometheusRecorder(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	r, err := NewPrometheusRecorder(registry)
+	assert.Nil(t, err)
+	record(r)
+
+	expected := `
+# HELP appconfiguration_cache_entries Number of features, properties and segments in the configurations in use, by collection and environment.
+# TYPE appconfiguration_cache_entries gauge
+appconfiguration_cache_entries{collection_id="c1",environment_id="dev",kind="features"} 3
+appconfiguration_cache_entries{collection_id="c1",environment_id="dev",kind="properties"} 2
+appconfiguration_cache_entries{collection_id="c1",environment_id="dev",kind="segments"} 1
+appconfiguration_cache_entries{collection_id="c1",environment_id="prod",kind="features"} 5
+appconfiguration_cache_entries{collection_id="c1",environment_id="prod",kind="properties"} 4
+appconfiguration_cache_entries{collection_id="c1",environment_id="prod",kind="segments"} 0
+# HELP appconfiguration_feature_evaluations_total Number of evaluations of each feature, by segment matched (empty when none).
+# TYPE appconfiguration_feature_evaluations_total counter
+appconfiguration_feature_evaluations_total{feature_id="discount",segment_id=""} 1
+appconfiguration_feature_evaluations_total{feature_id="discount",segment_id="gold"} 1
+# HELP appconfiguration_fetch_attempts_total Number of attempts to fetch the configurations from the server.
+# TYPE appconfiguration_fetch_attempts_total counter
+appconfiguration_fetch_attempts_total 2
+# HELP appconfiguration_fetch_failures_total Number of attempts to fetch the configurations from the server that failed.
+# TYPE appconfiguration_fetch_failures_total counter
+appconfiguration_fetch_failures_total 1
+# HELP appconfiguration_metering_batches_dropped_total Number of batches of usage data that could not be sent to the server.
+# TYPE appconfiguration_metering_batches_dropped_total counter
+appconfiguration_metering_batches_dropped_total 1
+# HELP appconfiguration_metering_batches_sent_total Number of batches of usage data sent to the server.
+# TYPE appconfiguration_metering_batches_sent_total counter
+appconfiguration_metering_batches_sent_total 1
+# HELP appconfiguration_property_evaluations_total Number of evaluations of each property, by segment matched (empty when none).
+# TYPE appconfiguration_property_evaluations_total counter
+appconfiguration_property_evaluations_total{property_id="banner",segment_id="gold"} 1
+# HELP appconfiguration_websocket_reconnects_total Number of times the websocket got connected again after it was disconnected.
+# TYPE appconfiguration_websocket_reconnects_total counter
+appconfiguration_websocket_reconnects_total 1
+`
+	assert.Nil(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"appconfiguration_cache_entries",
+		"appconfiguration_feature_evaluations_total",
+		"appconfiguration_fetch_attempts_total",
+		"appconfiguration_fetch_failures_total",
+		"appconfiguration_metering_batches_dropped_total",
+		"appconfiguration_metering_batches_sent_total",
+		"appconfiguration_property_evaluations_total",
+		"appconfiguration_websocket_reconnects_total"))
+	count, err := testutil.GatherAndCount(registry, "appconfiguration_fetch_duration_seconds", "appconfiguration_listener_duration_seconds")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	// a registry takes a single recorder, and is left as it was when it is refused
+	_, err = NewPrometheusRecorder(registry)
+	assert.True(t, errors.As(err, &prometheus.AlreadyRegisteredError{}))
+	other := prometheus.NewRegistry()
+	assert.Nil(t, other.Register(prometheus.NewCounter(prometheus.CounterOpts{Name: "appconfiguration_metering_batches_dropped_total"})))
+	_, err = NewPrometheusRecorder(other)
+	assert.NotNil(t, err)
+	count, err = testutil.GatherAndCount(other)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestOpenTelemetryRecorder(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	r, err := NewOpenTelemetryRecorder(provider.Meter("appconfiguration-go-sdk"))
+	assert.Nil(t, err)
+	collect := func() map[string]metricdata.Aggregation {
+		var rm metricdata.ResourceMetrics
+		assert.Nil(t, reader.Collect(context.Background(), &rm))
+		aggregations := make(map[string]metricdata.Aggregation)
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				aggregations[m.Name] = m.Data
+			}
+		}
+		return aggregations
+	}
+	sum := func(data metricdata.Aggregation, attributes ...string) int64 {
+		var total int64
+		for _, point := range data.(metricdata.Sum[int64]).DataPoints {
+			matches := true
+			for i := 0; i+1 < len(attributes); i += 2 {
+				value, ok := point.Attributes.Value(attribute.Key(attributes[i]))
+				matches = matches && ok && value.AsString() == attributes[i+1]
+			}
+			if matches {
+				total += point.Value
+			}
+		}
+		return total
+	}
+
+	// the cache size is not reported until it is recorded
+	_, ok := collect()["appconfiguration.cache.entries"]
+	assert.False(t, ok)
+
+	record(r)
+	aggregations := collect()
+	assert.Equal(t, int64(2), sum(aggregations["appconfiguration.feature.evaluations"], "feature_id", "discount"))
+	assert.Equal(t, int64(1), sum(aggregations["appconfiguration.feature.evaluations"], "feature_id", "discount", "segment_id", "gold"))
+	assert.Equal(t, int64(1), sum(aggregations["appconfiguration.property.evaluations"], "property_id", "banner", "segment_id", "gold"))
+	assert.Equal(t, int64(2), sum(aggregations["appconfiguration.fetch.attempts"]))
+	assert.Equal(t, int64(1), sum(aggregations["appconfiguration.fetch.failures"]))
+	assert.Equal(t, int64(1), sum(aggregations["appconfiguration.websocket.reconnects"]))
+	assert.Equal(t, int64(1), sum(aggregations["appconfiguration.metering.batches.sent"]))
+	assert.Equal(t, int64(1), sum(aggregations["appconfiguration.metering.batches.dropped"]))
+	fetchDuration := aggregations["appconfiguration.fetch.duration"].(metricdata.Histogram[float64]).DataPoints
+	assert.Equal(t, uint64(2), fetchDuration[0].Count)
+	assert.InDelta(t, 0.3, fetchDuration[0].Sum, 1e-9)
+	assert.Equal(t, uint64(1), aggregations["appconfiguration.listener.duration"].(metricdata.Histogram[float64]).DataPoints[0].Count)
+	cacheEntries := make(map[string]int64)
+	for _, point := range aggregations["appconfiguration.cache.entries"].(metricdata.Gauge[int64]).DataPoints {
+		environmentID, _ := point.Attributes.Value("environment_id")
+		kind, _ := point.Attributes.Value("kind")
+		cacheEntries[environmentID.AsString()+"/"+kind.AsString()] = point.Value
+	}
+	assert.Equal(t, map[string]int64{
+		"dev/features": 3, "dev/properties": 2, "dev/segments": 1,
+		"prod/features": 5, "prod/properties": 4, "prod/segments": 0,
+	}, cacheEntries)
+}